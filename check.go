@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// checkOutputs compares the generated outputs with what is already in the
+// output directory, printing a unified diff for every file that differs. It
+// returns false if anything is out of date.
+func checkOutputs(outputs []output) bool {
+	upToDate := true
+
+	for _, out := range outputs {
+		path := filepath.Join(*outputDir, out.filename)
+
+		existing, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			exitWithError(err)
+		}
+
+		diff := unifiedDiff("a/"+out.filename, "b/"+out.filename, string(existing), string(out.source))
+		if diff == "" {
+			continue
+		}
+
+		if existing == nil {
+			fmt.Fprintf(os.Stderr, "%s is missing\n", path)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s is out of date\n", path)
+		}
+		fmt.Print(diff)
+		upToDate = false
+	}
+
+	return upToDate
+}
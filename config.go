@@ -0,0 +1,361 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// Selection decides which TA-Lib functions get bindings. A function is
+// selected if it matches one of the include lists, or if both lists are
+// empty, and is not excluded.
+type Selection struct {
+	IncludeGroups    []string
+	IncludeFunctions []string
+	Excludes         []Exclusion
+}
+
+// Exclusion leaves out the functions matching a name or glob, recording why.
+type Exclusion struct {
+	Function string
+	Reason   string
+}
+
+var defaultSelection = Selection{
+	Excludes: []Exclusion{
+		{Function: "TRIX", Reason: "banned by the original generator"},
+	},
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// exclusion returns the exclusion matching spec, if any.
+func (s Selection) exclusion(spec FunctionSpec) (Exclusion, bool) {
+	for _, exclude := range s.Excludes {
+		if matched, _ := path.Match(exclude.Function, spec.Name); matched {
+			return exclude, true
+		}
+	}
+	return Exclusion{}, false
+}
+
+func (s Selection) included(spec FunctionSpec) bool {
+	if len(s.IncludeGroups) == 0 && len(s.IncludeFunctions) == 0 {
+		return true
+	}
+
+	for _, group := range s.IncludeGroups {
+		if group == spec.Group {
+			return true
+		}
+	}
+	return matchesAny(s.IncludeFunctions, spec.Name)
+}
+
+func (s Selection) validate() error {
+	patterns := append([]string{}, s.IncludeFunctions...)
+	for _, exclude := range s.Excludes {
+		patterns = append(patterns, exclude.Function)
+	}
+
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad function pattern %q: %v", pattern, err)
+		}
+	}
+
+	// Names, patterns and reasons end up in line comments in the generated
+	// code.
+	for _, group := range s.IncludeGroups {
+		if strings.ContainsAny(group, "\r\n") {
+			return fmt.Errorf("group %q must be a single line", group)
+		}
+	}
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, "\r\n") {
+			return fmt.Errorf("function pattern %q must be a single line", pattern)
+		}
+	}
+	for _, exclude := range s.Excludes {
+		if strings.ContainsAny(exclude.Reason, "\r\n") {
+			return fmt.Errorf("reason for excluding %s must be a single line", exclude.Function)
+		}
+	}
+	return nil
+}
+
+// checkGroups fails if the selection includes a group TA-Lib does not have,
+// which would otherwise quietly select nothing.
+func (s Selection) checkGroups(groups []GroupSpec) error {
+	names := []string{}
+	known := map[string]bool{}
+	for _, group := range groups {
+		names = append(names, group.Name)
+		known[group.Name] = true
+	}
+
+	for _, group := range s.IncludeGroups {
+		if !known[group] {
+			return fmt.Errorf("unknown group %q, TA-Lib has %q", group, names)
+		}
+	}
+	return nil
+}
+
+// readSelection reads a selection from a config file such as:
+//
+//	[include]
+//	groups = ["Overlap Studies", "Momentum Indicators"]
+//	functions = ["ATR", "CDL*"]
+//
+//	[[exclude]]
+//	function = "TRIX"
+//	reason = "banned by the original generator"
+func readSelection(filename string) (Selection, error) {
+	var selection Selection
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return selection, err
+	}
+
+	root, err := parseTOML(string(data))
+	if err != nil {
+		return selection, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	for key, value := range root {
+		switch key {
+		case "include":
+			table, ok := value.(map[string]interface{})
+			if !ok {
+				return selection, fmt.Errorf("%s: include must be a table", filename)
+			}
+			for key, value := range table {
+				list, ok := value.([]string)
+				if !ok {
+					return selection, fmt.Errorf("%s: include.%s must be an array of strings", filename, key)
+				}
+				switch key {
+				case "groups":
+					selection.IncludeGroups = list
+				case "functions":
+					selection.IncludeFunctions = list
+				default:
+					return selection, fmt.Errorf("%s: unknown key include.%s", filename, key)
+				}
+			}
+		case "exclude":
+			tables, ok := value.([]map[string]interface{})
+			if !ok {
+				return selection, fmt.Errorf("%s: exclude must be an array of tables", filename)
+			}
+			for _, table := range tables {
+				var exclude Exclusion
+				for key, value := range table {
+					s, ok := value.(string)
+					if !ok {
+						return selection, fmt.Errorf("%s: exclude.%s must be a string", filename, key)
+					}
+					switch key {
+					case "function":
+						exclude.Function = s
+					case "reason":
+						exclude.Reason = s
+					default:
+						return selection, fmt.Errorf("%s: unknown key exclude.%s", filename, key)
+					}
+				}
+				if exclude.Function == "" {
+					return selection, fmt.Errorf("%s: exclude is missing a function", filename)
+				}
+				selection.Excludes = append(selection.Excludes, exclude)
+			}
+		default:
+			return selection, fmt.Errorf("%s: unknown key %s", filename, key)
+		}
+	}
+
+	return selection, selection.validate()
+}
+
+// parseTOML parses the small subset of TOML the config file needs: tables,
+// arrays of tables, and keys holding a string or an array of strings. Tables
+// are returned as map[string]interface{} and arrays of tables as
+// []map[string]interface{}.
+func parseTOML(data string) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	current := root
+
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+
+		switch {
+		case line == "":
+			continue
+
+		case strings.HasPrefix(line, "[["):
+			if !strings.HasSuffix(line, "]]") {
+				return nil, fmt.Errorf("line %d: bad table header %q", lineNumber, line)
+			}
+			name := strings.TrimSpace(line[2 : len(line)-2])
+			tables, _ := root[name].([]map[string]interface{})
+			if _, exists := root[name]; exists && tables == nil {
+				return nil, fmt.Errorf("line %d: %s is already defined", lineNumber, name)
+			}
+			current = map[string]interface{}{}
+			root[name] = append(tables, current)
+
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: bad table header %q", lineNumber, line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if _, exists := root[name]; exists {
+				return nil, fmt.Errorf("line %d: %s is already defined", lineNumber, name)
+			}
+			current = map[string]interface{}{}
+			root[name] = current
+
+		default:
+			equals := strings.Index(line, "=")
+			if equals < 0 {
+				return nil, fmt.Errorf("line %d: expected key = value", lineNumber)
+			}
+			key := strings.Trim(strings.TrimSpace(line[:equals]), `"`)
+			text := strings.TrimSpace(line[equals+1:])
+
+			// Arrays may continue over several lines.
+			for strings.HasPrefix(text, "[") && !tomlArrayClosed(text) && i+1 < len(lines) {
+				i++
+				text += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+			}
+
+			value, rest, err := parseTOMLValue(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			if strings.TrimSpace(rest) != "" {
+				return nil, fmt.Errorf("line %d: unexpected %q after value", lineNumber, rest)
+			}
+			if _, exists := current[key]; exists {
+				return nil, fmt.Errorf("line %d: %s is already defined", lineNumber, key)
+			}
+			current[key] = value
+		}
+	}
+
+	return root, nil
+}
+
+// stripTOMLComment removes a trailing # comment that is not inside a string.
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch {
+		case quote != 0 && line[i] == '\\' && quote == '"':
+			i++
+		case quote != 0 && line[i] == quote:
+			quote = 0
+		case quote == 0 && (line[i] == '"' || line[i] == '\''):
+			quote = line[i]
+		case quote == 0 && line[i] == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func tomlArrayClosed(text string) bool {
+	_, _, err := parseTOMLValue(text)
+	return err == nil
+}
+
+// parseTOMLValue parses a string or an array of strings at the start of text
+// and returns it along with whatever follows it.
+func parseTOMLValue(text string) (interface{}, string, error) {
+	text = strings.TrimLeft(text, " \t")
+	if text == "" {
+		return nil, "", fmt.Errorf("missing value")
+	}
+
+	switch text[0] {
+	case '"', '\'':
+		return parseTOMLString(text)
+
+	case '[':
+		list := []string{}
+		text = strings.TrimLeft(text[1:], " \t")
+		for {
+			if strings.HasPrefix(text, "]") {
+				return list, text[1:], nil
+			}
+
+			value, rest, err := parseTOMLString(text)
+			if err != nil {
+				return nil, "", err
+			}
+			list = append(list, value)
+
+			rest = strings.TrimLeft(rest, " \t")
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimLeft(rest[1:], " \t")
+			} else if !strings.HasPrefix(rest, "]") {
+				return nil, "", fmt.Errorf("expected , or ] in array")
+			}
+			text = rest
+		}
+	}
+
+	return nil, "", fmt.Errorf("unsupported value %q, only strings and arrays of strings are allowed", text)
+}
+
+func parseTOMLString(text string) (string, string, error) {
+	if text == "" || (text[0] != '"' && text[0] != '\'') {
+		return "", "", fmt.Errorf("expected a string")
+	}
+
+	if text[0] == '\'' {
+		end := strings.IndexByte(text[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string")
+		}
+		return text[1 : end+1], text[end+2:], nil
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '"':
+			return sb.String(), text[i+1:], nil
+		case '\\':
+			i++
+			if i == len(text) {
+				return "", "", fmt.Errorf("unterminated string")
+			}
+			switch text[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\':
+				sb.WriteByte(text[i])
+			default:
+				return "", "", fmt.Errorf("unsupported escape \\%c", text[i])
+			}
+		default:
+			sb.WriteByte(text[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]interface{}
+	}{
+		{
+			name: "tables and keys",
+			data: "[include]\ngroups = [\"Overlap Studies\"]\nfunctions = \"ATR\"\n",
+			want: map[string]interface{}{
+				"include": map[string]interface{}{
+					"groups":    []string{"Overlap Studies"},
+					"functions": "ATR",
+				},
+			},
+		},
+		{
+			name: "comments",
+			data: "# leading comment\n[include] # after a header\nfunctions = [\"CDL#1\", 'a#b'] # after a value\n",
+			want: map[string]interface{}{
+				"include": map[string]interface{}{
+					"functions": []string{"CDL#1", "a#b"},
+				},
+			},
+		},
+		{
+			name: "multi-line arrays",
+			data: "[include]\nfunctions = [\n  \"ATR\", # average true range\n  \"CDL*\",\n]\n",
+			want: map[string]interface{}{
+				"include": map[string]interface{}{
+					"functions": []string{"ATR", "CDL*"},
+				},
+			},
+		},
+		{
+			name: "empty array",
+			data: "[include]\ngroups = []\n",
+			want: map[string]interface{}{
+				"include": map[string]interface{}{
+					"groups": []string{},
+				},
+			},
+		},
+		{
+			name: "escapes",
+			data: `key = "a\"b\\c\td"` + "\n" + `raw = 'a\"b'` + "\n",
+			want: map[string]interface{}{
+				"key": "a\"b\\c\td",
+				"raw": `a\"b`,
+			},
+		},
+		{
+			name: "arrays of tables",
+			data: "[[exclude]]\nfunction = \"TRIX\"\n\n[[exclude]]\nfunction = \"CDL*\"\nreason = \"noisy\"\n",
+			want: map[string]interface{}{
+				"exclude": []map[string]interface{}{
+					{"function": "TRIX"},
+					{"function": "CDL*", "reason": "noisy"},
+				},
+			},
+		},
+		{
+			name: "quoted keys",
+			data: "\"include\" = \"x\"\n",
+			want: map[string]interface{}{
+				"include": "x",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTOML(test.data)
+			if err != nil {
+				t.Fatalf("parseTOML() failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseTOML() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"duplicate key", "a = \"x\"\na = \"y\"\n", "line 2: a is already defined"},
+		{"duplicate table", "[include]\n[include]\n", "line 2: include is already defined"},
+		{"table after array of tables", "[[exclude]]\n[exclude]\n", "line 2: exclude is already defined"},
+		{"array of tables after table", "[exclude]\n[[exclude]]\n", "line 2: exclude is already defined"},
+		{"unclosed table header", "[include\n", "line 1: bad table header"},
+		{"unclosed array of tables header", "[[exclude]\n", "line 1: bad table header"},
+		{"missing equals", "[include]\ngroups\n", "line 2: expected key = value"},
+		{"missing value", "a =\n", "line 1: missing value"},
+		{"unsupported value", "a = 1\n", "line 1: unsupported value"},
+		{"unterminated string", "a = \"x\n", "line 1: unterminated string"},
+		{"unterminated raw string", "a = 'x\n", "line 1: unterminated string"},
+		{"unsupported escape", `a = "\q"` + "\n", `line 1: unsupported escape \q`},
+		{"trailing garbage", "a = \"x\" y\n", "line 1: unexpected"},
+		{"unclosed array", "a = [\"x\"\n", "line 1: expected , or ]"},
+		{"missing comma", "a = [\"x\" \"y\"]\n", "line 1: expected , or ]"},
+		{"array of non-strings", "a = [1]\n", "line 1: expected a string"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseTOML(test.data)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("parseTOML() error = %v, want it to contain %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestReadSelection(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.toml")
+	data := `
+[include]
+groups = ["Overlap Studies"]
+functions = ["ATR", "CDL*"]
+
+[[exclude]]
+function = "TRIX"
+reason = "banned by the original generator"
+`
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readSelection(filename)
+	if err != nil {
+		t.Fatalf("readSelection() failed: %v", err)
+	}
+	want := Selection{
+		IncludeGroups:    []string{"Overlap Studies"},
+		IncludeFunctions: []string{"ATR", "CDL*"},
+		Excludes:         []Exclusion{{Function: "TRIX", Reason: "banned by the original generator"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readSelection() = %#v, want %#v", got, want)
+	}
+}
+
+func TestReadSelectionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"unknown key", "[include]\nnames = []\n", "unknown key include.names"},
+		{"unknown table", "[other]\n", "unknown key other"},
+		{"include not a table", "include = \"x\"\n", "include must be a table"},
+		{"groups not an array", "[include]\ngroups = \"x\"\n", "include.groups must be an array of strings"},
+		{"exclude not an array of tables", "[exclude]\n", "exclude must be an array of tables"},
+		{"exclude without function", "[[exclude]]\nreason = \"x\"\n", "exclude is missing a function"},
+		{"bad pattern", "[include]\nfunctions = [\"[\"]\n", "bad function pattern"},
+		{"multi-line group", "[include]\ngroups = [\"Overlap Studies\\nfoo\"]\n", "must be a single line"},
+		{"multi-line function", "[include]\nfunctions = [\"ATR\\nfoo\"]\n", "must be a single line"},
+		{"multi-line exclusion", "[[exclude]]\nfunction = \"ATR\\nfoo\"\n", "must be a single line"},
+		{"multi-line reason", "[[exclude]]\nfunction = \"ATR\"\nreason = \"a\\nb\"\n", "must be a single line"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(filename, []byte(test.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := readSelection(filename)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("readSelection() error = %v, want it to contain %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckGroups(t *testing.T) {
+	groups := []GroupSpec{{Name: "Overlap Studies"}, {Name: "Momentum Indicators"}}
+
+	if err := (Selection{IncludeGroups: []string{"Overlap Studies"}}).checkGroups(groups); err != nil {
+		t.Errorf("checkGroups() failed for a known group: %v", err)
+	}
+
+	err := (Selection{IncludeGroups: []string{"Overlap Studys"}}).checkGroups(groups)
+	if err == nil || !strings.Contains(err.Error(), `unknown group "Overlap Studys"`) {
+		t.Errorf("checkGroups() error = %v, want an unknown group error", err)
+	}
+}
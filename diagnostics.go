@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	unsupportedSkip  = "skip"
+	unsupportedAbort = "abort"
+)
+
+// Diagnostic records a parameter of a TA-Lib function that the generator does
+// not know how to bind.
+type Diagnostic struct {
+	Function   string
+	ParamKind  string
+	ParamIndex int
+	ParamName  string
+	Type       string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s %d (%s) has unsupported type %s", d.Function, d.ParamKind, d.ParamIndex, d.ParamName, d.Type)
+}
+
+// unsupportedParameters returns a diagnostic for every parameter of spec the
+// generator does not know how to bind.
+func unsupportedParameters(spec FunctionSpec) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	for _, input := range spec.Inputs {
+		if input.Type != InputReal && input.Type != InputPrice {
+			diagnostics = append(diagnostics, Diagnostic{spec.Name, "input", input.Index, input.Name, input.Type.String()})
+		}
+	}
+
+	for _, output := range spec.Outputs {
+		if output.Type != OutputReal && output.Type != OutputInteger {
+			diagnostics = append(diagnostics, Diagnostic{spec.Name, "output", output.Index, output.Name, output.Type.String()})
+		}
+	}
+
+	return diagnostics
+}
+
+// defaultedParameters returns a diagnostic for every optional input of spec
+// with a type the generator does not know. Rather than leaving the function
+// unbound, such inputs are never set, so TA-Lib uses their default value; a
+// new TA-Lib release adding an optional input type then does not stop the
+// functions using it from being generated.
+func defaultedParameters(spec FunctionSpec) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, optInput := range spec.OptInputs {
+		if !optInput.IsSupported() {
+			diagnostics = append(diagnostics, Diagnostic{spec.Name, "optional input", optInput.Index, optInput.Name, optInput.Type.String()})
+		}
+	}
+	return diagnostics
+}
+
+func printDefaulted(specs []FunctionSpec) {
+	for _, spec := range specs {
+		for _, diagnostic := range defaultedParameters(spec) {
+			fmt.Fprintf(os.Stderr, "warning: %s, bound at its default value\n", diagnostic)
+		}
+	}
+}
+
+func printReport(diagnostics []Diagnostic, policy string) {
+	level := "warning"
+	if policy == unsupportedAbort {
+		level = "error"
+	}
+
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", level, diagnostic)
+	}
+}
+
+// printSummary is only called once the outputs have been written or checked,
+// so an aborted run never claims to have bound anything.
+func printSummary(numFunctions int, diagnostics []Diagnostic) {
+	unsupported := map[string]bool{}
+	for _, diagnostic := range diagnostics {
+		unsupported[diagnostic.Function] = true
+	}
+
+	fmt.Fprintf(os.Stderr, "%d functions bound, %d with unsupported parameters\n", numFunctions, len(unsupported))
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns the lines "1\n" to "n\n", with the lines in replace
+// swapped for their replacements.
+func numberedLines(n int, replace map[int]string) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := replace[i]; ok {
+			sb.WriteString(line)
+		} else {
+			sb.WriteString(strconv.Itoa(i) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	base := numberedLines(20, nil)
+
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "identical",
+			a:    base,
+			b:    base,
+			want: "",
+		},
+		{
+			name: "empty to non-empty",
+			a:    "",
+			b:    "x\ny\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "non-empty to empty",
+			a:    "x\ny\n",
+			b:    "",
+			want: "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-x\n-y\n",
+		},
+		{
+			name: "insertion",
+			a:    base,
+			b:    numberedLines(20, map[int]string{5: "5\nnew1\nnew2\n"}),
+			want: "--- a\n+++ b\n@@ -3,6 +3,8 @@\n 3\n 4\n 5\n+new1\n+new2\n 6\n 7\n 8\n",
+		},
+		{
+			name: "deletion",
+			a:    base,
+			b:    numberedLines(20, map[int]string{5: "", 6: ""}),
+			want: "--- a\n+++ b\n@@ -2,8 +2,6 @@\n 2\n 3\n 4\n-5\n-6\n 7\n 8\n 9\n",
+		},
+		{
+			name: "changes within two contexts share a hunk",
+			a:    base,
+			b:    numberedLines(20, map[int]string{2: "two\n", 9: "nine\n"}),
+			want: "--- a\n+++ b\n@@ -1,12 +1,12 @@\n 1\n-2\n+two\n 3\n 4\n 5\n 6\n 7\n 8\n-9\n+nine\n 10\n 11\n 12\n",
+		},
+		{
+			name: "changes further apart get their own hunks",
+			a:    base,
+			b:    numberedLines(20, map[int]string{2: "two\n", 15: "fifteen\n"}),
+			want: "--- a\n+++ b\n" +
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+				"@@ -12,7 +12,7 @@\n 12\n 13\n 14\n-15\n+fifteen\n 16\n 17\n 18\n",
+		},
+		{
+			name: "context is cut short at the edges of the file",
+			a:    base,
+			b:    numberedLines(20, map[int]string{1: "one\n", 20: "twenty\n"}),
+			want: "--- a\n+++ b\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -17,4 +17,4 @@\n 17\n 18\n 19\n-20\n+twenty\n",
+		},
+		{
+			name: "missing final newline",
+			a:    "a\nb",
+			b:    "a\nc",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := unifiedDiff("a", "b", test.a, test.b); got != test.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesIsShortest(t *testing.T) {
+	a := splitLines("a\nb\nc\na\nb\nb\na\n")
+	b := splitLines("c\nb\na\nb\na\nc\n")
+
+	ops := diffLines(a, b)
+
+	edits := 0
+	var rebuilt []string
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			rebuilt = append(rebuilt, a[op.aLine])
+		case diffInsert:
+			rebuilt = append(rebuilt, b[op.bLine])
+			edits++
+		case diffDelete:
+			edits++
+		}
+	}
+
+	if strings.Join(rebuilt, "") != strings.Join(b, "") {
+		t.Errorf("edit script builds %q, want %q", rebuilt, b)
+	}
+	// The example from Myers' paper, whose shortest edit script has 5 edits.
+	if edits != 5 {
+		t.Errorf("edit script has %d edits, want 5", edits)
+	}
+}
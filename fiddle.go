@@ -0,0 +1,175 @@
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+// fiddleTestCase is a value of an optional input that a generated test maps to
+// a fiddle value and back.
+type fiddleTestCase struct {
+	Function    string
+	Constructor string
+	Index       int
+	Actual      string
+}
+
+// fiddleBoundsCase is an optional input with the values a generated test
+// expects at both ends of the fiddle value range.
+type fiddleBoundsCase struct {
+	Function    string
+	Constructor string
+	Index       int
+	Low         string
+	High        string
+}
+
+// fiddleStepCase is a range with the step a generated test expects its fiddle
+// values to be quantized to.
+type fiddleStepCase struct {
+	Function    string
+	Constructor string
+	Index       int
+	Step        string
+	Decimals    int
+}
+
+// optInputTestCase is an optional input a generated test checks rejects NaN
+// and, if it takes integers, fractions.
+type optInputTestCase struct {
+	Function    string
+	Constructor string
+	Index       int
+	Name        string
+	Integer     bool
+}
+
+// singleValueTestCase is a function with a time period that cannot be 1, with
+// the inputs a generated test sets to check GoSingle on a single bar.
+type singleValueTestCase struct {
+	Function    string
+	Constructor string
+	RealInputs  []int
+	PriceInput  bool
+}
+
+type fiddleTestData struct {
+	Package      string
+	Cases        []fiddleTestCase
+	Bounds       []fiddleBoundsCase
+	Steps        []fiddleStepCase
+	OptInputs    []optInputTestCase
+	SingleValues []singleValueTestCase
+}
+
+// templateFloat parses a value back from the way formatFloat writes it into
+// the bindings.
+func templateFloat(value float64) float64 {
+	parsed, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'f', 10, 64), 64)
+	return parsed
+}
+
+// fiddleTestValues returns the values of an optional input worth testing: the
+// first, middle and last values of a range, or every item of a list.
+func fiddleTestValues(optInput OptInputSpec) []float64 {
+	switch {
+	case optInput.IsRange():
+		start := templateFloat(optInput.Range.SuggestedStart)
+		end := templateFloat(optInput.Range.SuggestedEnd)
+		step := templateFloat(optInput.FiddleStep())
+		if step <= 0 || end <= start {
+			return []float64{start, (start + end) / 2, end}
+		}
+
+		// As helper_rangeSteps and helper_fixRange in the generated
+		// fiddle_values.go.
+		n := int(math.Floor((end-start)/step+1e-9)) + 1
+		values := []float64{start, start + float64(n/2)*step, start + float64(n-1)*step}
+		for i, value := range values {
+			values[i], _ = strconv.ParseFloat(strconv.FormatFloat(value, 'f', optInput.FiddleDecimals(), 64), 64)
+		}
+		return values
+
+	case optInput.IsList():
+		values := []float64{}
+		for _, item := range optInput.List {
+			values = append(values, item.Value)
+		}
+		return values
+	}
+	return nil
+}
+
+// minTimePeriod returns the largest of the smallest values the function's time
+// periods allow, which is the shortest input GoSingle runs the function on, or
+// 0 if it has no time periods with a range.
+func (f FunctionSpec) minTimePeriod() float64 {
+	shortest := 0.0
+	for _, index := range f.TimePeriodIndexes() {
+		optInput := f.OptInputs[index]
+		if optInput.IsRange() && optInput.Range.Min > shortest {
+			shortest = optInput.Range.Min
+		}
+	}
+	return shortest
+}
+
+func newFiddleTestData(specs []FunctionSpec) fiddleTestData {
+	data := fiddleTestData{Package: *libraryName}
+	for _, spec := range specs {
+		if spec.minTimePeriod() > 1 {
+			singleValue := singleValueTestCase{Function: spec.Name, Constructor: spec.CamelCaseName}
+			for _, input := range spec.Inputs {
+				if input.Type == InputPrice {
+					singleValue.PriceInput = true
+				} else {
+					singleValue.RealInputs = append(singleValue.RealInputs, input.Index)
+				}
+			}
+			data.SingleValues = append(data.SingleValues, singleValue)
+		}
+
+		for _, optInput := range spec.OptInputs {
+			if optInput.IsSupported() {
+				data.OptInputs = append(data.OptInputs, optInputTestCase{
+					Function:    spec.Name,
+					Constructor: spec.CamelCaseName,
+					Index:       optInput.Index,
+					Name:        optInput.Name,
+					Integer:     optInput.Type == OptInputIntegerRange || optInput.Type == OptInputIntegerList,
+				})
+			}
+
+			if step := optInput.FiddleStep(); step > 0 {
+				data.Steps = append(data.Steps, fiddleStepCase{
+					Function:    spec.Name,
+					Constructor: spec.CamelCaseName,
+					Index:       optInput.Index,
+					Step:        strconv.FormatFloat(templateFloat(step), 'g', -1, 64),
+					Decimals:    optInput.FiddleDecimals(),
+				})
+			}
+
+			values := fiddleTestValues(optInput)
+			if len(values) > 0 {
+				data.Bounds = append(data.Bounds, fiddleBoundsCase{
+					Function:    spec.Name,
+					Constructor: spec.CamelCaseName,
+					Index:       optInput.Index,
+					Low:         strconv.FormatFloat(values[0], 'g', -1, 64),
+					High:        strconv.FormatFloat(values[len(values)-1], 'g', -1, 64),
+				})
+			}
+
+			for _, value := range values {
+				data.Cases = append(data.Cases, fiddleTestCase{
+					Function:    spec.Name,
+					Constructor: spec.CamelCaseName,
+					Index:       optInput.Index,
+					Actual:      strconv.FormatFloat(value, 'g', -1, 64),
+				})
+			}
+		}
+	}
+	return data
+}
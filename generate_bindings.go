@@ -1,812 +1,472 @@
 package main
 
 import (
+	"bytes"
+	"embed"
+	"flag"
 	"fmt"
+	"go/format"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
-	"unsafe"
+	"text/template"
 )
 
-/*
-#cgo LDFLAGS: -L/home/tom/dev/ta-lib/src/.libs/ -lta_lib -lm
-#include "/home/tom/dev/ta-lib/include/ta_abstract.h"
-char* getListAt(char **list, unsigned int idx)
-{
-	return list[idx];
+type bindingsData struct {
+	Package         string
+	TALibIncludeDir string
+	TALibLibDir     string
+	Selection       Selection
+	Omitted         []omission
+	Functions       []FunctionSpec
 }
-TA_IntegerDataPair getIntegerDataPairAt( TA_IntegerDataPair* data, unsigned int index )
-{
-	return data[index];
-}
-*/
-import "C"
-
-func getGroups() []string {
-	var table *C.TA_StringTable
-	retCode := C.TA_GroupTableAlloc(&table)
-
-	if retCode == C.TA_SUCCESS {
-		defer C.TA_FuncTableFree(table)
-		ret := make([]string, table.size)
-		for i := C.uint(0); i < table.size; i++ {
-			ret[i] = C.GoString(C.getListAt(table.string, i))
-		}
-		return ret
-	}
 
-	return []string{}
+type functionsData struct {
+	Package         string
+	TALibIncludeDir string
+	TALibLibDir     string
+	Wrappers        []wrapperData
 }
 
-func getFunctions(group string) []string {
-	var table *C.TA_StringTable
-	retCode := C.TA_FuncTableAlloc(C.CString(group), &table)
-
-	if retCode == C.TA_SUCCESS {
-		defer C.TA_FuncTableFree(table)
-		ret := make([]string, table.size)
-		for i := C.uint(0); i < table.size; i++ {
-			ret[i] = C.GoString(C.getListAt(table.string, i))
-		}
-		return ret
-	}
-
-	return []string{}
+type cgoPackageData struct {
+	Package         string
+	TALibIncludeDir string
+	TALibLibDir     string
 }
 
-func getFunctionHandle(functionName string) *C.TA_FuncHandle {
-	var handle *C.TA_FuncHandle
-	C.TA_GetFuncHandle(C.CString(functionName), &handle)
-	return handle
+type packageData struct {
+	Package string
 }
 
-func getFunctionInfo(handle *C.TA_FuncHandle) C.TA_FuncInfo {
-	var info *C.TA_FuncInfo
-	C.TA_GetFuncInfo(handle, &info)
-	return *info
+type functionArrayData struct {
+	Package   string
+	Functions []string
 }
 
-func writeToBindingsFile(data string) {
-	if _, err := bindingsOutputFile.WriteString(data); err != nil {
-		panic(err)
-	}
+type statsData struct {
+	Package                string
+	MaxFiddleValues        int
+	MaxOutputValues        int
+	NumFunctions           int
+	NumTimePeriodFunctions int
 }
 
-func initBindings() {
-	writeToBindingsFile(
-		"package " + kLibraryName + "\n\n" +
-			"import \"math\"\n\n" +
-			"/*\n" +
-			"#cgo LDFLAGS: -L/home/tom/dev/ta-lib/src/.libs/ -lta_lib -lm\n" +
-			"#include \"/home/tom/dev/ta-lib/include/ta_abstract.h\"\n" +
-
-			"*/\n" +
-			"import \"C\"\n\n",
-	)
-}
+//go:embed templates/*.tmpl
+var templateFS embed.FS
 
-func addStruct(name string) {
-	writeToBindingsFile(
-		"type " + name + " struct {\n" +
-			"\tparams *C.TA_ParamHolder\n" +
-			"\thandle *C.TA_FuncHandle\n\n" +
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"join": strings.Join,
+	"formatFloat": func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 10, 64)
+	},
+}).ParseFS(templateFS, "templates/*.tmpl"))
 
-			"\trealInputByIndex map[int][]C.TA_Real\n" +
-			"\tintegerOutputByIndex map[int][]C.TA_Integer\n" +
-			"\trealOutputByIndex map[int][]C.TA_Real\n\n" +
-
-			"\tfiddleValues []float64\n" +
-			"}\n\n",
-	)
-}
-
-func addInitFunction(name string, info C.TA_FuncInfo) {
-	writeToBindingsFile(
-		"func ( a *" + name + " ) init() {\n" +
-			"\thelper_paramHolderAlloc( a.handle, &a.params )\n" +
-			"\ta.realInputByIndex = make( map[int][]C.TA_Real )\n" +
-			"\ta.realOutputByIndex = make( map[int][]C.TA_Real )\n" +
-			"\ta.integerOutputByIndex = make( map[int][]C.TA_Integer )\n" +
-			"\ta.fiddleValues = make( []float64, " + strconv.Itoa(int(info.nbOptInput)) + " )\n\n",
-	)
-
-	for i := 0; i < int(info.nbOptInput); i++ {
-		var paramInfo *C.TA_OptInputParameterInfo
-		C.TA_GetOptInputParameterInfo(info.handle, C.uint(i), &paramInfo)
+func renderOutput(filename, templateName string, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, templateName, data); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
 
-		writeToBindingsFile(
-			"\ta.fiddleValues[" + strconv.Itoa(i) + "] = float64( " +
-				strconv.FormatFloat(float64(paramInfo.defaultValue), 'f', 10, 64) +
-				" )\n",
-		)
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: generated code does not parse: %v", filename, err)
 	}
+	return source, nil
+}
 
-	writeToBindingsFile("}\n\n")
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
 }
 
-func addGetNumInputsFunction(name string, info C.TA_FuncInfo) {
-	writeToBindingsFile(
-		"func ( a *" + name + " ) GetNumInputs() ( int ) {\n" +
-			"\treturn " + strconv.Itoa(int(info.nbInput)) + "\n" +
-			"}\n\n",
-	)
+// output is a generated file, rendered and formatted but not yet written.
+type output struct {
+	filename string
+	source   []byte
 }
 
-func addSetInputDataFunction(name string, info C.TA_FuncInfo) {
-	writeToBindingsFile(
-		"func ( a *" + name + " ) SetInputData( index int, data []float64 ) {\n",
-	)
-
-	for i := 0; i < int(info.nbInput); i++ {
-		var paramInfo *C.TA_InputParameterInfo
-		C.TA_GetInputParameterInfo(info.handle, C.uint(i), &paramInfo)
-
-		if paramInfo._type == C.TA_Input_Real {
-			writeToBindingsFile(
-				"\tif index == " + strconv.Itoa(i) + " {\n" +
-					"\t\tvar temp []C.TA_Real\n" +
-					"\t\thelper_convertGoFloat64ArrayToTaRealArray( data, &temp )\n" +
-					"\t\ta.realInputByIndex[index] = temp\n" +
-					"\t\thelper_setInputDataReal( a.params, index, &(a.realInputByIndex[index][0]) )\n" +
-					"\t\treturn\n" +
-					"\t}\n",
-			)
-		} else if paramInfo._type == C.TA_Input_Price {
-			// Do nothing, handled later...
-		} else {
-			panic("doh 1")
-		}
+func writeTempFile(out output) (string, error) {
+	path := filepath.Join(*outputDir, out.filename)
 
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
 	}
 
-	writeToBindingsFile(
-		"}\n\n",
-	)
-}
-
-func addSetPriceInputDataFunction(name string, info C.TA_FuncInfo) {
-	writeToBindingsFile(
-		"func ( a *" + name + " ) SetPriceInputData( open, high, low, close, volume, openInterest []float64 ) {\n",
-	)
-
-	for i := 0; i < int(info.nbInput); i++ {
-		var paramInfo *C.TA_InputParameterInfo
-		C.TA_GetInputParameterInfo(info.handle, C.uint(i), &paramInfo)
-
-		if paramInfo._type == C.TA_Input_Price {
-			writeToBindingsFile(
-				"\tvar temp []C.TA_Real\n" +
-					"\thelper_convertGoFloat64ArrayToTaRealArray( open, &temp )\n" +
-					"\ta.realInputByIndex[0] = temp\n" +
-					"\thelper_convertGoFloat64ArrayToTaRealArray( high, &temp )\n" +
-					"\ta.realInputByIndex[1] = temp\n" +
-					"\thelper_convertGoFloat64ArrayToTaRealArray( low, &temp )\n" +
-					"\ta.realInputByIndex[2] = temp\n" +
-					"\thelper_convertGoFloat64ArrayToTaRealArray( close, &temp )\n" +
-					"\ta.realInputByIndex[3] = temp\n" +
-					"\thelper_convertGoFloat64ArrayToTaRealArray( volume, &temp )\n" +
-					"\ta.realInputByIndex[4] = temp\n" +
-					"\thelper_convertGoFloat64ArrayToTaRealArray( openInterest, &temp )\n" +
-					"\ta.realInputByIndex[5] = temp\n" +
-					"\thelper_setInputDataPrice( \n" +
-					"\t\ta.params, " + strconv.Itoa(i) + ", \n" +
-					"\t\t&( a.realInputByIndex[0][0] ), \n" +
-					"\t\t&( a.realInputByIndex[1][0] ), \n" +
-					"\t\t&( a.realInputByIndex[2][0] ), \n" +
-					"\t\t&( a.realInputByIndex[3][0] ), \n" +
-					"\t\t&( a.realInputByIndex[4][0] ), \n" +
-					"\t\t&( a.realInputByIndex[5][0] ),\n" +
-					"\t)\n",
-			)
-			break
-		}
+	file, err := os.CreateTemp(*outputDir, "."+out.filename+".*.tmp")
+	if err != nil {
+		return "", err
 	}
 
-	writeToBindingsFile(
-		"}\n\n",
-	)
-}
-
-func addGetAndSetFiddleValuesFunctions(name string, info C.TA_FuncInfo) {
-	writeToBindingsFile(
-		"func ( a *" + name + " ) GetNumFiddleValues() int {\n" +
-			"\treturn " + strconv.Itoa(int(info.nbOptInput)) + "\n" +
-			"}\n" +
-			"\n" +
-			"func ( a *" + name + " ) GetFiddleValues() ( []float64 ) {\n" +
-			"\treturn a.fiddleValues\n" +
-			"}\n" +
-			"\n" +
-			"func ( a *" + name + " ) SetFiddleValues( v []float64 ) {\n" +
-			"\tif len( v ) != " + strconv.Itoa(int(info.nbOptInput)) + " {\n" +
-			"\t	panic( \"SetFiddleValues : bad number of fiddle values passed\" )\n" +
-			"\t}\n" +
-			"\ta.fiddleValues = v\n" +
-			"}\n\n",
-	)
-}
-
-func addGetNumOutputValues(name string, info C.TA_FuncInfo) {
-	writeToBindingsFile(
-		"func (a *" + name + " ) GetNumOutputValues() ( int ) {\n" +
-			"\treturn " + strconv.Itoa(int(info.nbOutput)) + "\n" +
-			"}\n\n",
-	)
+	_, err = file.Write(out.source)
+	if err == nil {
+		err = file.Chmod(mode)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
 }
 
-func addFixFiddleValueFunction(name string, info C.TA_FuncInfo) {
-	writeToBindingsFile(
-		"func (a *" + name + " ) FixFiddleValue( fiddleValueIndex int, inValue float64 ) ( float64 ) {\n",
-	)
-
-	for i := 0; i < int(info.nbOptInput); i++ {
-		var paramInfo *C.TA_OptInputParameterInfo
-		C.TA_GetOptInputParameterInfo(info.handle, C.uint(i), &paramInfo)
-
-		if paramInfo._type == C.TA_OptInput_IntegerRange {
-			integerRange := (*C.TA_IntegerRange)(unsafe.Pointer(paramInfo.dataSet))
-
-			writeToBindingsFile(
-				"\tif fiddleValueIndex == " + strconv.Itoa(i) + " {\n" +
-					fmt.Sprintf("\t\treturn float64( int( %d + ( float64( %d - %d ) * inValue ) ) )\n",
-						int(integerRange.suggested_start),
-						int(integerRange.suggested_end),
-						int(integerRange.suggested_start),
-					) +
-					"\t}\n\n",
-			)
-		} else if paramInfo._type == C.TA_OptInput_RealRange {
-			realRange := (*C.TA_RealRange)(unsafe.Pointer(paramInfo.dataSet))
-
-			writeToBindingsFile(
-				"\tif fiddleValueIndex == " + strconv.Itoa(i) + " {\n" +
-					fmt.Sprintf("\t\treturn %f + ( ( %f - %f ) * inValue )\n",
-						float64(realRange.suggested_start),
-						float64(realRange.suggested_end),
-						float64(realRange.suggested_start),
-					) +
-					"\t}\n\n",
-			)
-		} else if paramInfo._type == C.TA_OptInput_IntegerList {
-			integerList := (*C.TA_IntegerList)(unsafe.Pointer(paramInfo.dataSet))
-
-			writeToBindingsFile(
-				"\tif fiddleValueIndex == " + strconv.Itoa(i) + " {\n" +
-					fmt.Sprintf("\t\tindex := int( inValue * float64( %d ) )\n", int(integerList.nbElement)) +
-					"\t\tindexToRetMap := map[int]int {\n",
-			)
-
-			for i := 0; i < int(integerList.nbElement); i++ {
-
-				writeToBindingsFile(
-					"\t\t\t" + strconv.Itoa(i) + " : " +
-						strconv.Itoa(int(C.getIntegerDataPairAt(integerList.data, C.uint(i)).value)) + ",\n",
-				)
-			}
-
-			writeToBindingsFile(
-				"\t\t}\n" +
-					"\t\treturn float64( indexToRetMap[index] )\n" +
-					"\t}\n\n",
-			)
+// replaceFile renames source over target, first moving any existing target
+// aside. It returns the name the old target was moved to, or "" if there was
+// none.
+func replaceFile(source, target string) (string, error) {
+	if _, err := os.Stat(target); err != nil {
+		return "", os.Rename(source, target)
+	}
 
-		} else {
-			fmt.Println(paramInfo._type)
-			panic("doh 7")
-		}
+	backup, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.bak")
+	if err != nil {
+		return "", err
 	}
+	backup.Close()
 
-	writeToBindingsFile("\tpanic(\"invalid index passed to FixFiddleValue\")\n")
-	writeToBindingsFile("\treturn 0.0\n")
-	writeToBindingsFile("}\n\n")
+	if err := os.Rename(target, backup.Name()); err != nil {
+		os.Remove(backup.Name())
+		return "", err
+	}
+	if err := os.Rename(source, target); err != nil {
+		os.Rename(backup.Name(), target)
+		return "", err
+	}
+	return backup.Name(), nil
 }
 
-func addGoFunction(name string, info C.TA_FuncInfo) {
-
-	writeToBindingsFile(
-		"func ( a *" + name + " ) Go( outIndex int ) ( []float64 ) {\n" +
-			"\tstartIndex := 0\n",
-	)
-
-	writeToBindingsFile(
-		"\tfor i := 1; i < " + strconv.Itoa(int(info.nbInput)) + "; i++ {\n" +
-			"\t\tif len( a.realInputByIndex[i] ) != len( a.realInputByIndex[i - 1] ) {\n" +
-			"\t\t\tpanic(\"Input data has different lengths\")\n" +
-			"\t\t}\n" +
-			"\t}\n",
-	)
-
-	writeToBindingsFile(
-		"\tendIndex := len( a.realInputByIndex[0] ) - 1\n\n",
-	)
-
-	for i := 0; i < int(info.nbOptInput); i++ {
-		var paramInfo *C.TA_OptInputParameterInfo
-		C.TA_GetOptInputParameterInfo(info.handle, C.uint(i), &paramInfo)
-
-		if paramInfo._type == C.TA_OptInput_IntegerRange {
-			writeToBindingsFile(
-				"\thelper_setOptInputDataInteger( a.params, " + strconv.Itoa(i) + ", int( a.fiddleValues[" + strconv.Itoa(i) + "] ) )\n",
-			)
-		} else if paramInfo._type == C.TA_OptInput_RealRange {
-			writeToBindingsFile(
-				"\thelper_setOptInputDataReal( a.params, " + strconv.Itoa(i) + ", a.fiddleValues[" + strconv.Itoa(i) + "] )\n",
-			)
-		} else if paramInfo._type == C.TA_OptInput_IntegerList {
-			writeToBindingsFile(
-				"\thelper_setOptInputDataInteger( a.params, " + strconv.Itoa(i) + ", int( a.fiddleValues[" + strconv.Itoa(i) + "] ) )\n",
-			)
-		} else {
-			panic("doh 6")
+// writeOutputs writes every output to a temporary file next to its target and
+// only renames them into place once all of them have been written. If a rename
+// fails, the outputs already renamed are rolled back to the files they
+// replaced, so a failed run leaves the existing package untouched.
+func writeOutputs(outputs []output) error {
+	tempFilenames := []string{}
+	defer func() {
+		for _, tempFilename := range tempFilenames {
+			os.Remove(tempFilename)
 		}
-	}
-
-	writeToBindingsFile("\n")
+	}()
 
-	for i := 0; i < int(info.nbOutput); i++ {
-		var paramInfo *C.TA_OutputParameterInfo
-		C.TA_GetOutputParameterInfo(info.handle, C.uint(i), &paramInfo)
-
-		if paramInfo._type == C.TA_Output_Real {
-			writeToBindingsFile(
-				"\ta.realOutputByIndex[" + strconv.Itoa(i) + "] = make( []C.TA_Real, endIndex - startIndex + 1 )\n" +
-					"\thelper_setOutputParamRealPtr( a.params, " + strconv.Itoa(i) + ", &(a.realOutputByIndex[" + strconv.Itoa(i) + "][0] ) )\n",
-			)
-		} else if paramInfo._type == C.TA_Output_Integer {
-			writeToBindingsFile(
-				"\ta.integerOutputByIndex[" + strconv.Itoa(i) + "] = make( []C.TA_Integer, endIndex - startIndex + 1 )\n" +
-					"\thelper_setOutputParamIntegerPtr( a.params, " + strconv.Itoa(i) + ", &(a.integerOutputByIndex[" + strconv.Itoa(i) + "][0] ) )\n",
-			)
-		} else {
-			panic("doh 3")
+	for _, out := range outputs {
+		tempFilename, err := writeTempFile(out)
+		if err != nil {
+			return err
 		}
+		tempFilenames = append(tempFilenames, tempFilename)
 	}
 
-	writeToBindingsFile("\n")
-
-	writeToBindingsFile(
-		"\tvar temp, numElements C.TA_Integer\n" +
-			"\thelper_callFunction( a.params, startIndex, endIndex, &temp, &numElements )\n\n",
-	)
-
-	for i := 0; i < int(info.nbOutput); i++ {
-		var paramInfo *C.TA_OutputParameterInfo
-		C.TA_GetOutputParameterInfo(info.handle, C.uint(i), &paramInfo)
-
-		if paramInfo._type == C.TA_Output_Real {
-			writeToBindingsFile(
-				"\tif outIndex == " + strconv.Itoa(i) + " {\n" +
-					"\t\tvar ret []float64\n" +
-					"\t\thelper_convertTaRealArrayToGoFloat64Array( a.realOutputByIndex[outIndex], &ret )\n" +
-					"\t\treturn ret[:numElements]\n" +
-					"\t}\n",
-			)
-		} else if paramInfo._type == C.TA_Output_Integer {
-			writeToBindingsFile(
-				"\tif outIndex == " + strconv.Itoa(i) + " {\n" +
-					"\t\tvar ret []float64\n" +
-					"\t\thelper_convertTaIntegerArrayToGoFloat64Array( a.integerOutputByIndex[outIndex], &ret )\n" +
-					"\t\treturn ret[:numElements]\n" +
-					"\t}\n",
-			)
-		} else {
-			panic("doh 4")
+	backups := []string{}
+	for i, out := range outputs {
+		backup, err := replaceFile(tempFilenames[i], filepath.Join(*outputDir, out.filename))
+		if err != nil {
+			if rollbackErr := rollbackOutputs(outputs[:i], backups); rollbackErr != nil {
+				return fmt.Errorf("%v, and rolling back failed: %v", err, rollbackErr)
+			}
+			return err
 		}
+		backups = append(backups, backup)
 	}
 
-	writeToBindingsFile("\n")
-
-	// Add default return
-	for i := 0; i < int(info.nbOutput); i++ {
-		var paramInfo *C.TA_OutputParameterInfo
-		C.TA_GetOutputParameterInfo(info.handle, C.uint(i), &paramInfo)
-
-		if paramInfo._type == C.TA_Output_Real {
-			writeToBindingsFile(
-				"\tvar ret []float64\n" +
-					"\thelper_convertTaRealArrayToGoFloat64Array( a.realOutputByIndex[" + strconv.Itoa(i) + "], &ret )\n" +
-					"\treturn ret[:numElements]\n",
-			)
-			break
-		} else if paramInfo._type == C.TA_Output_Integer {
-			writeToBindingsFile(
-				"\tvar ret []float64\n" +
-					"\thelper_convertTaIntegerArrayToGoFloat64Array( a.integerOutputByIndex[" + strconv.Itoa(i) + "], &ret )\n" +
-					"\treturn ret[:numElements]\n",
-			)
-			break
-		} else {
-			panic("doh 5")
+	for _, backup := range backups {
+		if backup != "" {
+			os.Remove(backup)
 		}
 	}
-
-	writeToBindingsFile("}\n\n")
+	return nil
 }
 
-func addGoSingleFunction(name string, info C.TA_FuncInfo) {
+// rollbackOutputs puts back the files outputs replaced, and removes the ones
+// that did not replace anything.
+func rollbackOutputs(outputs []output, backups []string) error {
+	var firstErr error
+	for i, out := range outputs {
+		target := filepath.Join(*outputDir, out.filename)
 
-	writeToBindingsFile(
-		"func ( a *" + name + " ) GoSingle( outputIndex int ) ( float64 ) {\n",
-	)
-
-	if shouldBeInTimePeriodArray(info) {
-
-		timePeriodIndexes := []int{}
-		for i := 0; i < int(info.nbOptInput); i++ {
-			var paramInfo *C.TA_OptInputParameterInfo
-			C.TA_GetOptInputParameterInfo(info.handle, C.uint(i), &paramInfo)
-
-			if strings.Contains(C.GoString(paramInfo.displayName), "Period") {
-				timePeriodIndexes = append(timePeriodIndexes, i)
-			}
-		}
-
-		if len(timePeriodIndexes) > 0 {
-			for _, timePeriodIndex := range timePeriodIndexes {
-				writeToBindingsFile(
-					"\ta.fiddleValues[" + strconv.Itoa(timePeriodIndex) + "] = float64( len( a.realInputByIndex[0] ) )\n",
-				)
-			}
-
-			writeToBindingsFile(
-				"\tret := a.Go( outputIndex )\n" +
-					"\tif len( ret ) > 0 {\n" +
-					"\t\tret := ret[0]\n" +
-					"\t\tif math.IsNaN( ret ) || math.IsInf( ret, 0 ) {\n" +
-					"\t\t\treturn 0\n" +
-					"\t\t} else {\n" +
-					"\t\t\treturn ret\n" +
-					"\t\t}\n" +
-					"\t} else {\n" +
-					"\t\treturn 0\n" +
-					"\t}\n",
-			)
+		var err error
+		if backups[i] == "" {
+			err = os.Remove(target)
 		} else {
-			writeToBindingsFile(
-				"\treturn 0\n",
-			)
+			err = os.Rename(backups[i], target)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
 		}
-	} else {
-		writeToBindingsFile(
-			"\treturn 0\n",
-		)
 	}
-
-	writeToBindingsFile(
-		"}\n\n",
-	)
+	return firstErr
 }
 
-func addPubicCreateFunction(goFuncName, name string, info C.TA_FuncInfo) {
-	writeToBindingsFile(
-		"func " + goFuncName + "() TA_Function {\n" +
-			"\tvar ret " + name + "\n" +
-			"\thelper_getFunctionHandle( \"" + C.GoString(info.name) + "\", &ret.handle )\n" +
-			"\tret.init()\n" +
-			"\treturn &ret\n" +
-			"}\n\n",
-	)
-}
+func createStats(specs []FunctionSpec) statsData {
+	stats := statsData{Package: *libraryName}
 
-func addSeparator() {
-	writeToBindingsFile(
-		"/*--------------------------------------------------------------------------------------------------------*/\n\n",
-	)
-}
+	for _, spec := range specs {
+		if len(spec.OptInputs) > stats.MaxFiddleValues {
+			stats.MaxFiddleValues = len(spec.OptInputs)
+		}
+
+		if len(spec.Outputs) > stats.MaxOutputValues {
+			stats.MaxOutputValues = len(spec.Outputs)
+		}
 
-func infoContainsRealInput(info C.TA_FuncInfo) bool {
-	for i := 0; i < int(info.nbInput); i++ {
-		var paramInfo *C.TA_InputParameterInfo
-		C.TA_GetInputParameterInfo(info.handle, C.uint(i), &paramInfo)
+		if spec.InFunctionArray() {
+			stats.NumFunctions++
+		}
 
-		if paramInfo._type == C.TA_Input_Real {
-			return true
+		if spec.InTimePeriodArray() {
+			stats.NumTimePeriodFunctions++
 		}
 	}
-	return false
+
+	return stats
 }
 
-func shouldBeInFunctionArray(info C.TA_FuncInfo) bool {
-	for i := 0; i < int(info.nbInput); i++ {
-		var paramInfo *C.TA_InputParameterInfo
-		C.TA_GetInputParameterInfo(info.handle, C.uint(i), &paramInfo)
+var (
+	outputDir       = flag.String("out", "../gotalib", "directory the generated package is written to")
+	libraryName     = flag.String("package", "gotalib", "name of the generated Go package")
+	taLibIncludeDir = flag.String("talib-include", "/usr/local/include/ta-lib", "TA-Lib include directory used in the generated cgo preamble")
+	taLibLibDir     = flag.String("talib-lib", "/usr/local/lib", "TA-Lib library directory used in the generated cgo preamble")
+
+	bindingsFilename        = flag.String("bindings-file", "bindings.go", "name of the generated bindings file")
+	functionArrayFilename   = flag.String("function-array-file", "function_array.go", "name of the generated function array file")
+	timePeriodArrayFilename = flag.String("time-period-array-file", "time_period_array.go", "name of the generated time period array file")
+	taFunctionFilename      = flag.String("ta-function-file", "ta_function.go", "name of the generated TA_Function interface file")
+	functionsFilename       = flag.String("functions-file", "functions.go", "name of the generated file of typed functions")
+	statsFilename           = flag.String("stats-file", "ta_stats.go", "name of the generated stats file")
+	retCodeFilename         = flag.String("ret-code-file", "ret_code.go", "name of the generated TA-Lib return code file")
+	maTypeFilename          = flag.String("ma-type-file", "ma_type.go", "name of the generated moving average type file")
+	fiddleValuesFilename    = flag.String("fiddle-values-file", "fiddle_values.go", "name of the generated fiddle value mapping file")
+	searchSpaceFilename     = flag.String("search-space-file", "search_space.go", "name of the generated search space file")
+	fiddleTestFilename      = flag.String("fiddle-test-file", "fiddle_values_test.go", "name of the generated fiddle value test file")
+
+	snapshotFilename = flag.String("from", "", "generate from a snapshot written by dump instead of from TA-Lib")
+	onUnsupported    = flag.String("on-unsupported", unsupportedAbort, "what to do with functions that have parameters the generator cannot bind: skip or abort")
+	configFilename   = flag.String("config", "", "config file selecting the functions to generate bindings for")
+	check            = flag.Bool("check", false, "compare the generated code with the files in -out instead of writing it, and fail if they differ")
+)
 
-		if paramInfo._type == C.TA_Input_Price {
-			if !infoContainsRealInput(info) {
-				return true
-			} else {
-				return false
+// omission records why a TA-Lib function has no binding.
+type omission struct {
+	Function string
+	Reason   string
+}
+
+// selectFunctions returns the functions to generate bindings for, along with
+// why every other function was left out. Functions with parameters the
+// generator cannot bind are left out and reported.
+func selectFunctions(snapshot Snapshot, selection Selection) ([]FunctionSpec, []omission, []Diagnostic) {
+	selected := []FunctionSpec{}
+	omitted := []omission{}
+	diagnostics := []Diagnostic{}
+
+	for _, spec := range snapshot.Functions {
+		if exclude, excluded := selection.exclusion(spec); excluded {
+			reason := "excluded"
+			if exclude.Reason != "" {
+				reason += ", " + exclude.Reason
 			}
+			omitted = append(omitted, omission{spec.Name, reason})
+			continue
 		}
-	}
-	return false
-}
 
-func createBinding(info C.TA_FuncInfo) {
+		if !selection.included(spec) {
+			omitted = append(omitted, omission{spec.Name, "not selected by the include lists"})
+			continue
+		}
 
-	if !shouldBeInFunctionArray(info) && !shouldBeInTimePeriodArray(info) {
-		return
-	}
+		if !spec.HasBinding() {
+			omitted = append(omitted, omission{spec.Name, "has neither real nor price inputs"})
+			continue
+		}
 
-	camelCaseName := C.GoString(info.camelCaseName)
-	structName := strings.ToLower(string(camelCaseName[0])) + camelCaseName[1:] + "_struct"
-
-	addStruct(structName)
-	addInitFunction(structName, info)
-	addGetNumInputsFunction(structName, info)
-	addSetInputDataFunction(structName, info)
-	addSetPriceInputDataFunction(structName, info)
-	addGetAndSetFiddleValuesFunctions(structName, info)
-	addFixFiddleValueFunction(structName, info)
-	addGetNumOutputValues(structName, info)
-	addGoFunction(structName, info)
-	addGoSingleFunction(structName, info)
-	addPubicCreateFunction(camelCaseName, structName, info)
-	addSeparator()
-}
+		unsupported := unsupportedParameters(spec)
+		if len(unsupported) > 0 {
+			omitted = append(omitted, omission{spec.Name, "has parameters of unsupported types"})
+			diagnostics = append(diagnostics, unsupported...)
+			continue
+		}
 
-func writeFunctionArrayFile(data string) {
-	if _, err := functionArrayOutputFile.WriteString(data); err != nil {
-		panic(err)
+		selected = append(selected, spec)
 	}
-}
 
-func initFunctionArray() {
-	writeFunctionArrayFile(
-		"package " + kLibraryName + "\n\n" +
-			"var FunctionArray = []func()(TA_Function) {\n",
-	)
+	return selected, omitted, diagnostics
 }
 
-func createFunctionArray(info C.TA_FuncInfo) {
-	if shouldBeInFunctionArray(info) {
-		writeFunctionArrayFile(
-			"\t" + C.GoString(info.camelCaseName) + ",\n",
-		)
+func renderOutputs(specs []FunctionSpec, selection Selection, omitted []omission) ([]output, error) {
+	bindings := bindingsData{
+		Package:         *libraryName,
+		TALibIncludeDir: *taLibIncludeDir,
+		TALibLibDir:     *taLibLibDir,
+		Selection:       selection,
+		Omitted:         omitted,
 	}
-}
-
-func shutdownFunctionArray() {
-	writeFunctionArrayFile(
-		"}\n",
-	)
-}
-
-func writeTimePeriodArrayFile(data string) {
-	if _, err := timePeriodArrayOutputFile.WriteString(data); err != nil {
-		panic(err)
+	functions := functionsData{
+		Package:         *libraryName,
+		TALibIncludeDir: *taLibIncludeDir,
+		TALibLibDir:     *taLibLibDir,
 	}
-}
-
-func initTimePeriodArray() {
-	writeTimePeriodArrayFile(
-		"package " + kLibraryName + "\n\n" +
-
-			"var TimePeriodFunctionArray = []func()(TA_Function) {\n",
-	)
-}
+	functionArray := functionArrayData{Package: *libraryName}
+	timePeriodArray := functionArrayData{Package: *libraryName}
 
-func shouldBeInTimePeriodArray(info C.TA_FuncInfo) bool {
-	for i := 0; i < int(info.nbInput); i++ {
-		var paramInfo *C.TA_InputParameterInfo
-		C.TA_GetInputParameterInfo(info.handle, C.uint(i), &paramInfo)
+	for _, spec := range specs {
+		bindings.Functions = append(bindings.Functions, spec)
 
-		if paramInfo._type == C.TA_Input_Real {
-			return true
+		if spec.InFunctionArray() {
+			functionArray.Functions = append(functionArray.Functions, spec.CamelCaseName)
 		}
-	}
-	return false
-}
 
-func createTimePeriodArray(info C.TA_FuncInfo) {
-	if !shouldBeInTimePeriodArray(info) {
-		return
+		if len(spec.TimePeriodIndexes()) > 0 {
+			timePeriodArray.Functions = append(timePeriodArray.Functions, spec.CamelCaseName)
+		}
 	}
 
-	timePeriodIndexes := []int{}
-	for i := 0; i < int(info.nbOptInput); i++ {
-		var paramInfo *C.TA_OptInputParameterInfo
-		C.TA_GetOptInputParameterInfo(info.handle, C.uint(i), &paramInfo)
-
-		if strings.Contains(C.GoString(paramInfo.displayName), "Period") {
-			timePeriodIndexes = append(timePeriodIndexes, i)
+	outputs := []struct {
+		filename     string
+		templateName string
+		data         interface{}
+	}{
+		{*bindingsFilename, "bindings.go.tmpl", bindings},
+		{*functionsFilename, "functions.go.tmpl", functions},
+		{*functionArrayFilename, "function_array.go.tmpl", functionArray},
+		{*timePeriodArrayFilename, "time_period_array.go.tmpl", timePeriodArray},
+		{*taFunctionFilename, "ta_function.go.tmpl", packageData{Package: *libraryName}},
+		{*statsFilename, "ta_stats.go.tmpl", createStats(specs)},
+		{*retCodeFilename, "ret_code.go.tmpl", cgoPackageData{*libraryName, *taLibIncludeDir, *taLibLibDir}},
+		{*maTypeFilename, "ma_type.go.tmpl", newMATypeData(specs)},
+		{*fiddleValuesFilename, "fiddle_values.go.tmpl", packageData{Package: *libraryName}},
+		{*searchSpaceFilename, "search_space.go.tmpl", bindings},
+		{*fiddleTestFilename, "fiddle_values_test.go.tmpl", newFiddleTestData(specs)},
+	}
+
+	rendered := []output{}
+	for _, out := range outputs {
+		source, err := renderOutput(out.filename, out.templateName, out.data)
+		if err != nil {
+			return nil, err
 		}
+		rendered = append(rendered, output{filename: out.filename, source: source})
 	}
 
-	if len(timePeriodIndexes) > 0 {
-		writeTimePeriodArrayFile(
-			"\t" + C.GoString(info.camelCaseName) + ",\n",
-		)
+	// The typed functions are named after TA-Lib's functions, which may already
+	// be taken by identifiers of the other files, so they are only added once
+	// those are known.
+	names, err := packageNames(rendered)
+	if err != nil {
+		return nil, err
 	}
-}
-
-func shutdownTimePeriodArray() {
-	writeTimePeriodArrayFile(
-		"}\n",
-	)
-}
-
-func createTaFunctionFile() {
-	if _, err := taFunctionOutputFile.WriteString(
-		"package " + kLibraryName + "\n\n" +
-			"type TA_Function interface {\n" +
-			"\tinit()\n\n" +
-			"\tGetNumInputs() ( int )\n" +
-			"\tSetInputData( int, []float64 )\n" +
-			"\tSetPriceInputData( []float64, []float64, []float64, []float64, []float64, []float64 )\n\n" +
-			"\tGetNumFiddleValues() ( int )\n" +
-			"\tGetFiddleValues() ( []float64 )\n" +
-			"\tFixFiddleValue( int, float64 ) ( float64 )\n" +
-			"\tSetFiddleValues( []float64 )\n\n" +
-			"\tGetNumOutputValues() ( int )\n" +
-			"\tGo( int ) ( []float64 )\n" +
-			"\tGoSingle( int ) ( float64 )\n" +
-			"}\n",
-	); err != nil {
-		panic(err)
+	for _, spec := range specs {
+		functions.Wrappers = append(functions.Wrappers, newWrapperData(spec, names))
 	}
-}
-
-func writeToStatsFile(data string) {
-	if _, err := statsOutputFile.WriteString(data); err != nil {
-		panic(err)
+	for i := range rendered {
+		if rendered[i].filename != *functionsFilename {
+			continue
+		}
+		source, err := renderOutput(*functionsFilename, "functions.go.tmpl", functions)
+		if err != nil {
+			return nil, err
+		}
+		rendered[i].source = source
 	}
+	return rendered, nil
 }
 
-func createStatsFile() {
-	writeToStatsFile(
-		"package " + kLibraryName + "\n\n",
-	)
-
-	maxFiddleValues := 0
-	maxOutputValues := 0
-	lenFunctionArray := 0
-	lenTimePeriodArray := 0
-
-	groups := getGroups()
-	for _, group := range groups {
-		functions := getFunctions(group)
-		for _, function := range functions {
-
-			found := false
-			for _, bannedFunction := range bannedFunctions {
-				if function == bannedFunction {
-					found = true
-				}
-			}
-
-			if !found {
-				handle := getFunctionHandle(function)
-				info := getFunctionInfo(handle)
-
-				if !shouldBeInFunctionArray(info) && !shouldBeInTimePeriodArray(info) {
-					continue
-				}
+// stringList is a flag that can be given more than once.
+type stringList []string
 
-				if int(info.nbOptInput) > maxFiddleValues {
-					maxFiddleValues = int(info.nbOptInput)
-				}
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
 
-				if int(info.nbOutput) > maxOutputValues {
-					maxOutputValues = int(info.nbOutput)
-				}
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
 
-				if shouldBeInFunctionArray(info) {
-					lenFunctionArray++
-				}
+var (
+	includeGroups    stringList
+	includeFunctions stringList
+	excludeFunctions stringList
+)
 
-				if shouldBeInTimePeriodArray(info) {
-					lenTimePeriodArray++
-				}
-			}
+func init() {
+	flag.Var(&includeGroups, "group", "generate bindings for the functions in this TA-Lib group, may be repeated")
+	flag.Var(&includeFunctions, "function", "generate bindings for the functions matching this name or glob, may be repeated")
+	flag.Var(&excludeFunctions, "exclude", "leave out the functions matching NAME[:REASON], may be repeated")
+}
+
+// loadSelection reads the selection from -config, or starts from the default
+// one, and adds the selection flags to it.
+func loadSelection() (Selection, error) {
+	selection := defaultSelection
+	if *configFilename != "" {
+		var err error
+		if selection, err = readSelection(*configFilename); err != nil {
+			return selection, err
 		}
 	}
 
-	writeToStatsFile(
-		"const (\n" +
-			fmt.Sprintf("\tMaxFiddleValues = %v\n", maxFiddleValues) +
-			fmt.Sprintf("\tMaxOutputValues = %v\n", maxOutputValues) +
-			fmt.Sprintf("\tNumFunctions = %v\n", lenFunctionArray) +
-			fmt.Sprintf("\tNumTimePeriodFunctions = %v\n", lenTimePeriodArray) +
-			")",
-	)
-}
-
-const (
-	kBindingsFilename        = "../gotalib/bindings.go"
-	kFunctionArrayFilename   = "../gotalib/function_array.go"
-	kTimePeriodArrayFilename = "../gotalib/time_period_array.go"
-	kTaFunctionFilename      = "../gotalib/ta_function.go"
-	kStatsOutputFilename     = "../gotalib/ta_stats.go"
+	selection.IncludeGroups = append(selection.IncludeGroups, includeGroups...)
+	selection.IncludeFunctions = append(selection.IncludeFunctions, includeFunctions...)
+	for _, exclude := range excludeFunctions {
+		name, reason, _ := strings.Cut(exclude, ":")
+		selection.Excludes = append(selection.Excludes, Exclusion{Function: name, Reason: reason})
+	}
 
-	kLibraryName = "gotalib"
-)
+	return selection, selection.validate()
+}
 
-var (
-	bindingsOutputFile        *os.File
-	functionArrayOutputFile   *os.File
-	timePeriodArrayOutputFile *os.File
-	taFunctionOutputFile      *os.File
-	statsOutputFile           *os.File
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "dump" {
+		dump(os.Args[2:])
+		return
+	}
 
-	bannedFunctions = []string{
-		"TRIX",
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
 	}
-)
 
-func init() {
-	var err error
-	bindingsOutputFile, err = os.OpenFile(kBindingsFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	snapshot, err := loadSnapshot(*snapshotFilename)
 	if err != nil {
-		panic(err)
+		exitWithError(err)
 	}
 
-	functionArrayOutputFile, err = os.OpenFile(kFunctionArrayFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
-	if err != nil {
-		panic(err)
+	if *onUnsupported != unsupportedSkip && *onUnsupported != unsupportedAbort {
+		exitWithError(fmt.Errorf("-on-unsupported must be %q or %q, not %q", unsupportedSkip, unsupportedAbort, *onUnsupported))
 	}
 
-	timePeriodArrayOutputFile, err = os.OpenFile(kTimePeriodArrayFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	selection, err := loadSelection()
 	if err != nil {
-		panic(err)
+		exitWithError(err)
+	}
+	if err := selection.checkGroups(snapshot.Groups); err != nil {
+		exitWithError(err)
 	}
 
-	taFunctionOutputFile, err = os.OpenFile(kTaFunctionFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
-	if err != nil {
-		panic(err)
+	specs, omitted, diagnostics := selectFunctions(snapshot, selection)
+	printDefaulted(specs)
+	printReport(diagnostics, *onUnsupported)
+	if len(diagnostics) > 0 && *onUnsupported == unsupportedAbort {
+		exitWithError(fmt.Errorf("aborting, rerun with -on-unsupported=%s to generate without these functions", unsupportedSkip))
 	}
 
-	statsOutputFile, err = os.OpenFile(kStatsOutputFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	outputs, err := renderOutputs(specs, selection, omitted)
 	if err != nil {
-		panic(err)
+		exitWithError(err)
 	}
-}
-
-func main() {
-
-	initBindings()
-	initFunctionArray()
-	initTimePeriodArray()
-
-	// handle := getFunctionHandle( "CDLINVERTEDHAMMER" )
-	// info := getFunctionInfo( handle )
-	// createBinding( info )
-	// createFunctionArray( info )
-	// createTimePeriodArray( info )
-
-	groups := getGroups()
-	for _, group := range groups {
-		functions := getFunctions(group)
-		for _, function := range functions {
-
-			found := false
-			for _, bannedFunction := range bannedFunctions {
-				if function == bannedFunction {
-					found = true
-				}
-			}
-
-			if !found {
-				handle := getFunctionHandle(function)
-				info := getFunctionInfo(handle)
 
-				createBinding(info)
-				createFunctionArray(info)
-				createTimePeriodArray(info)
-			}
+	if *check {
+		if !checkOutputs(outputs) {
+			os.Exit(1)
 		}
+		printSummary(len(specs), diagnostics)
+		return
 	}
 
-	shutdownFunctionArray()
-	shutdownTimePeriodArray()
-
-	bindingsOutputFile.Close()
-	functionArrayOutputFile.Close()
-	timePeriodArrayOutputFile.Close()
-
-	createTaFunctionFile()
-	taFunctionOutputFile.Close()
-
-	createStatsFile()
-	statsOutputFile.Close()
+	if err := writeOutputs(outputs); err != nil {
+		exitWithError(err)
+	}
+	printSummary(len(specs), diagnostics)
 }
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// readDir returns the contents of the files in dir by name, and "<dir>" for
+// directories.
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			files[entry.Name()] = "<dir>"
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name()] = string(data)
+	}
+	return files
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWriteOutputs(t *testing.T) {
+	setOutputDir(t, t.TempDir())
+	writeFiles(t, *outputDir, map[string]string{"a.go": "old a", "b.go": "old b"})
+
+	err := writeOutputs([]output{
+		{"a.go", []byte("new a")},
+		{"c.go", []byte("new c")},
+	})
+	if err != nil {
+		t.Fatalf("writeOutputs() failed: %v", err)
+	}
+
+	want := map[string]string{"a.go": "new a", "b.go": "old b", "c.go": "new c"}
+	if got := readDir(t, *outputDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("output directory holds %v, want %v", got, want)
+	}
+}
+
+func TestWriteOutputsRollsBack(t *testing.T) {
+	setOutputDir(t, t.TempDir())
+	writeFiles(t, *outputDir, map[string]string{"a.go": "old a", "b.go": "old b"})
+
+	// A non-empty directory cannot be moved aside over a file, so the rename
+	// of d.go fails after a.go, b.go and c.go have been renamed into place.
+	if err := os.MkdirAll(filepath.Join(*outputDir, "d.go", "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeOutputs([]output{
+		{"a.go", []byte("new a")},
+		{"b.go", []byte("new b")},
+		{"c.go", []byte("new c")},
+		{"d.go", []byte("new d")},
+	})
+	if err == nil {
+		t.Fatal("writeOutputs() succeeded with a directory in the way")
+	}
+
+	// Every file is as it was, and nothing temporary is left behind.
+	want := map[string]string{"a.go": "old a", "b.go": "old b", "d.go": "<dir>"}
+	if got := readDir(t, *outputDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("output directory holds %v, want %v", got, want)
+	}
+}
+
+func TestGeneratedCodeTypeChecks(t *testing.T) {
+	outputs := generateFrom(t, filepath.Join("testdata", "snapshot.json"))
+
+	helpers, err := os.ReadFile(filepath.Join("testdata", "helpers.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs = append(outputs, output{"helpers.go", helpers})
+
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+	for _, out := range outputs {
+		file, err := parser.ParseFile(fset, out.filename, out.source, 0)
+		if err != nil {
+			t.Fatalf("generated code does not parse: %v", err)
+		}
+		files = append(files, file)
+	}
+
+	config := types.Config{
+		FakeImportC: true,
+		Importer:    importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			t.Error(err)
+		},
+	}
+	pkg, _ := config.Check(*libraryName, fset, files, nil)
+
+	// The fixture's T3 has "T3" as its camel case name, so its constructor
+	// takes the name and the typed function has to make do with another.
+	for name, want := range map[string]string{
+		"T3":     "func() TA_Function",
+		"T3Func": "func(real []float64, timePeriod int, vFactor float64) (outReal []float64, begIdx int, err error)",
+	} {
+		object := pkg.Scope().Lookup(name)
+		if object == nil {
+			t.Errorf("%s is not declared", name)
+			continue
+		}
+		if got := types.TypeString(object.Type(), types.RelativeTo(pkg)); got != want {
+			t.Errorf("%s has type %s, want %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strconv"
+	"unicode"
+)
+
+type maTypeValue struct {
+	Name  string
+	Value int
+	Label string
+}
+
+type maTypeData struct {
+	Package string
+	Values  []maTypeValue
+}
+
+// newMATypeData builds the MAType enum from the labels of the first moving
+// average type list among specs. Every TA-Lib function taking a moving average
+// type shares the same list.
+func newMATypeData(specs []FunctionSpec) maTypeData {
+	data := maTypeData{Package: *libraryName}
+
+	for _, spec := range specs {
+		for _, optInput := range spec.OptInputs {
+			if !optInput.IsMAType() {
+				continue
+			}
+
+			seen := map[string]bool{}
+			for _, item := range optInput.List {
+				value := int(item.Value)
+				name := "MAType" + maTypeIdentifier(item.Label)
+				if name == "MAType" || seen[name] {
+					name = "MAType" + strconv.Itoa(value)
+				}
+				seen[name] = true
+				data.Values = append(data.Values, maTypeValue{name, value, item.Label})
+			}
+			return data
+		}
+	}
+	return data
+}
+
+// maTypeIdentifier keeps the letters and digits of a label, so "T3" stays
+// "T3".
+func maTypeIdentifier(label string) string {
+	ident := []rune{}
+	for _, r := range label {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			ident = append(ident, r)
+		}
+	}
+	return string(ident)
+}
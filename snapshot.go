@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// snapshotVersion is bumped whenever the snapshot layout changes in a way
+// older generators cannot read.
+const snapshotVersion = 1
+
+// Snapshot is everything the generator needs from the TA-Lib abstract API, so
+// bindings can be generated on machines without TA-Lib installed.
+type Snapshot struct {
+	Version      int            `json:"version"`
+	TALibVersion string         `json:"taLibVersion"`
+	Groups       []GroupSpec    `json:"groups"`
+	Functions    []FunctionSpec `json:"functions"`
+}
+
+// GroupSpec lists the functions TA-Lib reports for a group, in TA-Lib order.
+type GroupSpec struct {
+	Name      string   `json:"name"`
+	Functions []string `json:"functions"`
+}
+
+func readSnapshot(filename string) (Snapshot, error) {
+	var snapshot Snapshot
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return snapshot, err
+	}
+
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return snapshot, fmt.Errorf("%s: snapshot version %d is not supported, expected %d", filename, snapshot.Version, snapshotVersion)
+	}
+
+	return snapshot, nil
+}
+
+func writeSnapshot(filename string, snapshot Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if filename == "" || filename == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(filename, data, 0666)
+}
+
+// loadSnapshot reads the metadata from a snapshot file if one is given,
+// otherwise straight from TA-Lib.
+func loadSnapshot(filename string) (Snapshot, error) {
+	if filename != "" {
+		return readSnapshot(filename)
+	}
+	return loadTALibSnapshot()
+}
+
+func dump(args []string) {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	out := flags.String("out", "-", "file the snapshot is written to, - for stdout")
+	flags.Parse(args)
+
+	snapshot, err := loadTALibSnapshot()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if err := writeSnapshot(*out, snapshot); err != nil {
+		exitWithError(err)
+	}
+}
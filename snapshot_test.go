@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testSnapshots returns the snapshots offline generation is tested with: the
+// fixture in testdata and any snapshot committed under snapshots.
+func testSnapshots(t *testing.T) []string {
+	committed, err := filepath.Glob(filepath.Join("snapshots", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append([]string{filepath.Join("testdata", "snapshot.json")}, committed...)
+}
+
+// generateFrom renders the default selection from a snapshot, as
+// "-from filename" does.
+func generateFrom(t *testing.T, filename string) []output {
+	t.Helper()
+
+	snapshot, err := readSnapshot(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	specs, omitted, diagnostics := selectFunctions(snapshot, defaultSelection)
+	if len(diagnostics) > 0 {
+		t.Fatalf("%s has unsupported parameters: %v", filename, diagnostics)
+	}
+
+	outputs, err := renderOutputs(specs, defaultSelection, omitted)
+	if err != nil {
+		t.Fatalf("%s: %v", filename, err)
+	}
+	return outputs
+}
+
+// setOutputDir points -out at dir for the rest of the test.
+func setOutputDir(t *testing.T, dir string) {
+	previous := *outputDir
+	*outputDir = dir
+	t.Cleanup(func() { *outputDir = previous })
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for _, filename := range testSnapshots(t) {
+		snapshot, err := readSnapshot(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		roundTrip := filepath.Join(t.TempDir(), "snapshot.json")
+		if err := writeSnapshot(roundTrip, snapshot); err != nil {
+			t.Fatal(err)
+		}
+
+		// Generating from the rewritten snapshot must give the same code.
+		before, after := generateFrom(t, filename), generateFrom(t, roundTrip)
+		for i := range before {
+			if string(before[i].source) != string(after[i].source) {
+				t.Errorf("%s: %s changed after a round trip through writeSnapshot", filename, before[i].filename)
+			}
+		}
+	}
+}
+
+func TestCheckFromSnapshot(t *testing.T) {
+	for _, filename := range testSnapshots(t) {
+		setOutputDir(t, t.TempDir())
+
+		outputs := generateFrom(t, filename)
+		if err := writeOutputs(outputs); err != nil {
+			t.Fatal(err)
+		}
+		if !checkOutputs(generateFrom(t, filename)) {
+			t.Errorf("%s: -check reports freshly generated code as out of date", filename)
+		}
+
+		stale := filepath.Join(*outputDir, *statsFilename)
+		if err := os.WriteFile(stale, []byte("package gotalib\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if checkOutputs(generateFrom(t, filename)) {
+			t.Errorf("%s: -check does not notice that %s is out of date", filename, *statsFilename)
+		}
+	}
+}
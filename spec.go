@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// enumString names a TA-Lib enum value. Values the generator does not know
+// about are kept as "TypeName(N)" so they survive a round trip through a
+// snapshot.
+func enumString(typeName string, names []string, value int) string {
+	if value >= 0 && value < len(names) {
+		return names[value]
+	}
+	return fmt.Sprintf("%s(%d)", typeName, value)
+}
+
+func parseEnum(typeName string, names []string, text string, value *int) error {
+	for i, name := range names {
+		if text == name {
+			*value = i
+			return nil
+		}
+	}
+
+	if _, err := fmt.Sscanf(text, typeName+"(%d)", value); err != nil {
+		return fmt.Errorf("unknown %s %q", typeName, text)
+	}
+	return nil
+}
+
+// InputType mirrors TA_InputParameterType.
+type InputType int
+
+const (
+	InputPrice InputType = iota
+	InputReal
+	InputInteger
+)
+
+var inputTypeNames = []string{"price", "real", "integer"}
+
+func (t InputType) String() string {
+	return enumString("InputType", inputTypeNames, int(t))
+}
+
+func (t InputType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *InputType) UnmarshalText(text []byte) error {
+	return parseEnum("InputType", inputTypeNames, string(text), (*int)(t))
+}
+
+// OptInputType mirrors TA_OptInputParameterType.
+type OptInputType int
+
+const (
+	OptInputRealRange OptInputType = iota
+	OptInputRealList
+	OptInputIntegerRange
+	OptInputIntegerList
+)
+
+var optInputTypeNames = []string{"realRange", "realList", "integerRange", "integerList"}
+
+func (t OptInputType) String() string {
+	return enumString("OptInputType", optInputTypeNames, int(t))
+}
+
+func (t OptInputType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *OptInputType) UnmarshalText(text []byte) error {
+	return parseEnum("OptInputType", optInputTypeNames, string(text), (*int)(t))
+}
+
+// OutputType mirrors TA_OutputParameterType.
+type OutputType int
+
+const (
+	OutputReal OutputType = iota
+	OutputInteger
+)
+
+var outputTypeNames = []string{"real", "integer"}
+
+func (t OutputType) String() string {
+	return enumString("OutputType", outputTypeNames, int(t))
+}
+
+func (t OutputType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *OutputType) UnmarshalText(text []byte) error {
+	return parseEnum("OutputType", outputTypeNames, string(text), (*int)(t))
+}
+
+// FunctionSpec describes a single TA-Lib function, as reported by TA_GetFuncInfo
+// and the TA_Get*ParameterInfo calls.
+type FunctionSpec struct {
+	Name          string `json:"name"`
+	Group         string `json:"group"`
+	Hint          string `json:"hint"`
+	CamelCaseName string `json:"camelCaseName"`
+	Flags         uint32 `json:"flags"`
+
+	Inputs    []InputSpec    `json:"inputs"`
+	OptInputs []OptInputSpec `json:"optInputs"`
+	Outputs   []OutputSpec   `json:"outputs"`
+}
+
+// InputSpec describes a TA_InputParameterInfo. For price inputs Flags holds the
+// TA_IN_PRICE_* components the function reads.
+type InputSpec struct {
+	Index int       `json:"index"`
+	Type  InputType `json:"type"`
+	Name  string    `json:"name"`
+	Flags uint32    `json:"flags"`
+}
+
+// OptInputSpec describes a TA_OptInputParameterInfo. Range is set for the range
+// types and List for the list types.
+type OptInputSpec struct {
+	Index        int          `json:"index"`
+	Type         OptInputType `json:"type"`
+	Name         string       `json:"name"`
+	DisplayName  string       `json:"displayName"`
+	Hint         string       `json:"hint"`
+	Flags        uint32       `json:"flags"`
+	DefaultValue float64      `json:"defaultValue"`
+
+	Range *RangeSpec     `json:"range,omitempty"`
+	List  []ListItemSpec `json:"list,omitempty"`
+}
+
+// RangeSpec describes a TA_IntegerRange or TA_RealRange. Precision is only
+// meaningful for real ranges.
+type RangeSpec struct {
+	Min                float64 `json:"min"`
+	Max                float64 `json:"max"`
+	Precision          int     `json:"precision"`
+	SuggestedStart     float64 `json:"suggestedStart"`
+	SuggestedEnd       float64 `json:"suggestedEnd"`
+	SuggestedIncrement float64 `json:"suggestedIncrement"`
+}
+
+// ListItemSpec describes a TA_IntegerDataPair or TA_RealDataPair.
+type ListItemSpec struct {
+	Value float64 `json:"value"`
+	Label string  `json:"label"`
+}
+
+// OutputSpec describes a TA_OutputParameterInfo.
+type OutputSpec struct {
+	Index int        `json:"index"`
+	Type  OutputType `json:"type"`
+	Name  string     `json:"name"`
+	Flags uint32     `json:"flags"`
+}
+
+// IsMAType reports whether the optional input selects a moving average type,
+// such as optInMAType or optInSlowMAType.
+func (o OptInputSpec) IsMAType() bool {
+	return o.Type == OptInputIntegerList && strings.HasSuffix(o.Name, "MAType")
+}
+
+// IsSupported reports whether the generator knows the optional input's type,
+// and so passes its value to TA-Lib.
+func (o OptInputSpec) IsSupported() bool {
+	switch o.Type {
+	case OptInputRealRange, OptInputRealList, OptInputIntegerRange, OptInputIntegerList:
+		return true
+	}
+	return false
+}
+
+// IsRange reports whether the optional input is a range fiddle values can be
+// mapped onto.
+func (o OptInputSpec) IsRange() bool {
+	return (o.Type == OptInputIntegerRange || o.Type == OptInputRealRange) && o.Range != nil
+}
+
+// IsList reports whether the optional input is a list fiddle values can be
+// mapped onto.
+func (o OptInputSpec) IsList() bool {
+	return (o.Type == OptInputIntegerList || o.Type == OptInputRealList) && len(o.List) > 0
+}
+
+// FiddleStep returns the step fiddle values of a range are quantized to: its
+// suggested increment, at least 1 for an integer range, or 0 for a real range
+// without one and for lists.
+func (o OptInputSpec) FiddleStep() float64 {
+	switch {
+	case !o.IsRange():
+		return 0
+	case o.Type == OptInputIntegerRange:
+		return math.Max(1, math.Round(o.Range.SuggestedIncrement))
+	case o.Range.SuggestedIncrement > 0:
+		return o.Range.SuggestedIncrement
+	}
+	return 0
+}
+
+// FiddleDecimals returns the decimal places a stepped range's values are
+// rounded to: as many as its suggested start or increment has, as written into
+// the bindings, so stepping adds no floating point noise.
+func (o OptInputSpec) FiddleDecimals() int {
+	if o.FiddleStep() == 0 {
+		return 0
+	}
+	decimals := decimalPlaces(o.Range.SuggestedStart)
+	if stepDecimals := decimalPlaces(o.FiddleStep()); stepDecimals > decimals {
+		decimals = stepDecimals
+	}
+	return decimals
+}
+
+// decimalPlaces counts the decimal places of value as formatFloat writes it.
+func decimalPlaces(value float64) int {
+	text := strings.TrimRight(strconv.FormatFloat(value, 'f', 10, 64), "0")
+	return len(text) - strings.Index(text, ".") - 1
+}
+
+// ListMin returns the smallest value of a list optional input.
+func (o OptInputSpec) ListMin() float64 {
+	min := math.Inf(1)
+	for _, item := range o.List {
+		min = math.Min(min, item.Value)
+	}
+	return min
+}
+
+// ListMax returns the largest value of a list optional input.
+func (o OptInputSpec) ListMax() float64 {
+	max := math.Inf(-1)
+	for _, item := range o.List {
+		max = math.Max(max, item.Value)
+	}
+	return max
+}
+
+// MATypeOptInputNames returns the names of the optional inputs that select a
+// moving average type.
+func (f FunctionSpec) MATypeOptInputNames() []string {
+	names := []string{}
+	for _, optInput := range f.OptInputs {
+		if optInput.IsMAType() {
+			names = append(names, optInput.Name)
+		}
+	}
+	return names
+}
+
+func (f FunctionSpec) StructName() string {
+	return strings.ToLower(string(f.CamelCaseName[0])) + f.CamelCaseName[1:] + "_struct"
+}
+
+func (f FunctionSpec) hasInputType(inputType InputType) bool {
+	for _, input := range f.Inputs {
+		if input.Type == inputType {
+			return true
+		}
+	}
+	return false
+}
+
+// PriceInputIndex returns the index of the first price input, or -1 if there
+// is none.
+func (f FunctionSpec) PriceInputIndex() int {
+	for _, input := range f.Inputs {
+		if input.Type == InputPrice {
+			return input.Index
+		}
+	}
+	return -1
+}
+
+// PriceInputFlags returns the TA_IN_PRICE_* components read by the price
+// input, or 0 if there is none.
+func (f FunctionSpec) PriceInputFlags() uint32 {
+	for _, input := range f.Inputs {
+		if input.Type == InputPrice {
+			return input.Flags
+		}
+	}
+	return 0
+}
+
+// priceComponentUse is a price component, at its position in
+// TA_SetInputParamPricePtr, and whether the price input reads it.
+type priceComponentUse struct {
+	Position int
+	Name     string
+	Used     bool
+}
+
+func (f FunctionSpec) PriceComponents() []priceComponentUse {
+	flags := f.PriceInputFlags()
+	components := []priceComponentUse{}
+	for i, component := range priceComponents {
+		components = append(components, priceComponentUse{i, component.name, flags&component.flag != 0})
+	}
+	return components
+}
+
+func (f FunctionSpec) InFunctionArray() bool {
+	return f.hasInputType(InputPrice) && !f.hasInputType(InputReal)
+}
+
+func (f FunctionSpec) InTimePeriodArray() bool {
+	return f.hasInputType(InputReal)
+}
+
+func (f FunctionSpec) HasBinding() bool {
+	return f.InFunctionArray() || f.InTimePeriodArray()
+}
+
+// TimePeriodIndexes returns the indexes of the optional inputs that hold a
+// period, for functions in the time period array.
+func (f FunctionSpec) TimePeriodIndexes() []int {
+	timePeriodIndexes := []int{}
+	if !f.InTimePeriodArray() {
+		return timePeriodIndexes
+	}
+
+	for _, optInput := range f.OptInputs {
+		if strings.Contains(optInput.DisplayName, "Period") {
+			timePeriodIndexes = append(timePeriodIndexes, optInput.Index)
+		}
+	}
+	return timePeriodIndexes
+}
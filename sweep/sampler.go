@@ -0,0 +1,97 @@
+package sweep
+
+import (
+	"math/rand"
+)
+
+// Sampler picks points in fiddle value space, [0, 1] in each dimension.
+type Sampler interface {
+	Sample(dims int) [][]float64
+}
+
+type grid struct {
+	points int
+}
+
+// Grid samples points evenly spaced fiddle values in each dimension, from 0
+// to 1 inclusive, and every combination of them. Fiddle values map onto the
+// suggested range of each optional input, so a grid of 10 points over a
+// period suggested from 4 to 200 tries 4, 25, 47 and so on up to 200.
+func Grid(points int) Sampler {
+	return grid{points}
+}
+
+func (g grid) Sample(dims int) [][]float64 {
+	if g.points <= 0 {
+		return nil
+	}
+
+	axis := make([]float64, g.points)
+	for i := range axis {
+		axis[i] = 0.5
+		if g.points > 1 {
+			axis[i] = float64(i) / float64(g.points-1)
+		}
+	}
+
+	samples := [][]float64{{}}
+	for d := 0; d < dims; d++ {
+		next := make([][]float64, 0, len(samples)*len(axis))
+		for _, sample := range samples {
+			for _, value := range axis {
+				next = append(next, append(append([]float64{}, sample...), value))
+			}
+		}
+		samples = next
+	}
+	return samples
+}
+
+type random struct {
+	n    int
+	seed int64
+}
+
+// Random samples n points uniformly, the same points for the same seed.
+func Random(n int, seed int64) Sampler {
+	return random{n, seed}
+}
+
+func (r random) Sample(dims int) [][]float64 {
+	rng := rand.New(rand.NewSource(r.seed))
+	samples := make([][]float64, r.n)
+	for i := range samples {
+		samples[i] = make([]float64, dims)
+		for d := range samples[i] {
+			samples[i][d] = rng.Float64()
+		}
+	}
+	return samples
+}
+
+type latinHypercube struct {
+	n    int
+	seed int64
+}
+
+// LatinHypercube samples n points so that, in each dimension, every one of n
+// equal strata of [0, 1] holds exactly one of them. This covers each
+// parameter's range more evenly than Random with the same number of points.
+func LatinHypercube(n int, seed int64) Sampler {
+	return latinHypercube{n, seed}
+}
+
+func (l latinHypercube) Sample(dims int) [][]float64 {
+	rng := rand.New(rand.NewSource(l.seed))
+	samples := make([][]float64, l.n)
+	for i := range samples {
+		samples[i] = make([]float64, dims)
+	}
+
+	for d := 0; d < dims; d++ {
+		for i, stratum := range rng.Perm(l.n) {
+			samples[i][d] = (float64(stratum) + rng.Float64()) / float64(l.n)
+		}
+	}
+	return samples
+}
@@ -0,0 +1,145 @@
+// Package sweep runs a TA-Lib function generated by gotalib-generate over many
+// combinations of its optional inputs. Combinations are chosen in fiddle value
+// space, so every function is swept alike, by a grid, uniform random sampling
+// or Latin hypercube sampling, and mapped onto concrete parameters with
+// FixFiddleValue.
+package sweep
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Function is the part of gotalib's TA_Function a sweep uses, so any generated
+// function can be swept without this package importing gotalib.
+type Function interface {
+	GetNumFiddleValues() int
+	FixFiddleValue(int, float64) float64
+	SetFiddleValuesE([]float64) error
+	GoAllE() ([][]float64, error)
+}
+
+// Config describes a sweep.
+type Config struct {
+	// New creates an instance of the function. Each worker gets its own,
+	// since a function holds its inputs and parameters.
+	New func() Function
+
+	// Setup sets the input data on a new instance.
+	Setup func(Function) error
+
+	Sampler Sampler
+
+	// Workers is the number of evaluations run at once, runtime.NumCPU() if
+	// zero.
+	Workers int
+}
+
+// Result is the outcome of running the function with one combination of
+// parameters. Outputs holds every output, in output order, unless Err is set.
+type Result struct {
+	Params  []float64
+	Outputs [][]float64
+	Err     error
+}
+
+// Key formats parameters as the key of their result, such as "20,2,2,1".
+func Key(params []float64) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		parts[i] = strconv.FormatFloat(param, 'g', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Run evaluates every combination of parameters the sampler picks, and returns
+// the results by Key. Samples that map onto the same parameters are evaluated
+// once. Run fails if Setup fails; errors running the function are reported in
+// the results.
+func Run(config Config) (map[string]Result, error) {
+	if config.New == nil || config.Setup == nil || config.Sampler == nil {
+		return nil, fmt.Errorf("sweep: New, Setup and Sampler must all be set")
+	}
+
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Map the samples onto parameters, dropping duplicates.
+	f := config.New()
+	dims := f.GetNumFiddleValues()
+	combinations := [][]float64{}
+	seen := map[string]bool{}
+	for _, sample := range config.Sampler.Sample(dims) {
+		params := make([]float64, dims)
+		for i := range params {
+			params[i] = f.FixFiddleValue(i, sample[i])
+		}
+		if key := Key(params); !seen[key] {
+			seen[key] = true
+			combinations = append(combinations, params)
+		}
+	}
+	if workers > len(combinations) {
+		workers = len(combinations)
+	}
+
+	jobs := make(chan []float64)
+	results := make(chan Result)
+	setupErrs := make(chan error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			f := config.New()
+			if err := config.Setup(f); err != nil {
+				setupErrs <- err
+				// Keep draining so the other workers can finish.
+				for range jobs {
+				}
+				return
+			}
+			for params := range jobs {
+				results <- evaluate(f, params)
+			}
+		}()
+	}
+
+	go func() {
+		for _, params := range combinations {
+			jobs <- params
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+		close(setupErrs)
+	}()
+
+	byKey := make(map[string]Result, len(combinations))
+	for result := range results {
+		byKey[Key(result.Params)] = result
+	}
+	if err := <-setupErrs; err != nil {
+		return nil, fmt.Errorf("sweep: setting up inputs: %w", err)
+	}
+	return byKey, nil
+}
+
+func evaluate(f Function, params []float64) Result {
+	result := Result{Params: params}
+
+	if err := f.SetFiddleValuesE(params); err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Outputs, result.Err = f.GoAllE()
+	return result
+}
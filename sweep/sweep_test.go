@@ -0,0 +1,225 @@
+package sweep
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStats is shared by every instance of a fakeFunction.
+type fakeStats struct {
+	mu        sync.Mutex
+	runs      map[string]int
+	active    int
+	maxActive int
+}
+
+func (s *fakeStats) start(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[key]++
+	s.active++
+	if s.active > s.maxActive {
+		s.maxActive = s.active
+	}
+}
+
+func (s *fakeStats) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+}
+
+// fakeFunction has one fiddle value per entry of buckets, mapping [0, 1] onto
+// that many whole numbers, and two outputs: the sum and the product of its
+// fiddle values.
+type fakeFunction struct {
+	buckets []int
+	stats   *fakeStats
+	values  []float64
+}
+
+func newFake(stats *fakeStats, buckets ...int) func() Function {
+	return func() Function {
+		return &fakeFunction{buckets: buckets, stats: stats}
+	}
+}
+
+func (f *fakeFunction) GetNumFiddleValues() int {
+	return len(f.buckets)
+}
+
+func (f *fakeFunction) FixFiddleValue(index int, inValue float64) float64 {
+	n := f.buckets[index]
+	return math.Min(float64(n-1), math.Floor(inValue*float64(n)))
+}
+
+func (f *fakeFunction) SetFiddleValuesE(values []float64) error {
+	if values[0] < 0 {
+		return errors.New("negative")
+	}
+	f.values = values
+	return nil
+}
+
+func (f *fakeFunction) GoAllE() ([][]float64, error) {
+	f.stats.start(Key(f.values))
+	defer f.stats.stop()
+	time.Sleep(time.Millisecond)
+
+	sum, product := 0.0, 1.0
+	for _, value := range f.values {
+		sum += value
+		product *= value
+	}
+	return [][]float64{{sum}, {product}}, nil
+}
+
+func noSetup(Function) error {
+	return nil
+}
+
+func TestGrid(t *testing.T) {
+	samples := Grid(4).Sample(3)
+	if len(samples) != 64 {
+		t.Fatalf("Grid(4).Sample(3) returned %d samples, want 64", len(samples))
+	}
+
+	seen := map[string]bool{}
+	for _, sample := range samples {
+		seen[Key(sample)] = true
+	}
+	if len(seen) != 64 {
+		t.Errorf("Grid(4).Sample(3) returned %d distinct samples, want 64", len(seen))
+	}
+
+	for d := 0; d < 3; d++ {
+		axis := map[float64]bool{}
+		for _, sample := range samples {
+			axis[sample[d]] = true
+		}
+		want := map[float64]bool{0: true, 1.0 / 3: true, 2.0 / 3: true, 1: true}
+		if !reflect.DeepEqual(axis, want) {
+			t.Errorf("Grid(4) axis %d has values %v, want %v", d, axis, want)
+		}
+	}
+
+	if got := Grid(1).Sample(2); !reflect.DeepEqual(got, [][]float64{{0.5, 0.5}}) {
+		t.Errorf("Grid(1).Sample(2) = %v, want [[0.5 0.5]]", got)
+	}
+}
+
+func TestLatinHypercube(t *testing.T) {
+	const n = 10
+	samples := LatinHypercube(n, 1).Sample(3)
+	if len(samples) != n {
+		t.Fatalf("LatinHypercube(%d, 1).Sample(3) returned %d samples", n, len(samples))
+	}
+
+	for d := 0; d < 3; d++ {
+		strata := make([]int, n)
+		for _, sample := range samples {
+			if sample[d] < 0 || sample[d] >= 1 {
+				t.Fatalf("sample %v is outside [0, 1)", sample)
+			}
+			strata[int(sample[d]*n)]++
+		}
+		for stratum, count := range strata {
+			if count != 1 {
+				t.Errorf("dimension %d has %d samples in stratum %d, want 1", d, count, stratum)
+			}
+		}
+	}
+}
+
+func TestSamplersRepeatForSeed(t *testing.T) {
+	samplers := map[string]func(seed int64) Sampler{
+		"Random":         func(seed int64) Sampler { return Random(20, seed) },
+		"LatinHypercube": func(seed int64) Sampler { return LatinHypercube(20, seed) },
+	}
+
+	for name, sampler := range samplers {
+		first := sampler(7).Sample(4)
+		if again := sampler(7).Sample(4); !reflect.DeepEqual(first, again) {
+			t.Errorf("%s gave different samples for the same seed", name)
+		}
+		if other := sampler(8).Sample(4); reflect.DeepEqual(first, other) {
+			t.Errorf("%s gave the same samples for different seeds", name)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	stats := &fakeStats{runs: map[string]int{}}
+	results, err := Run(Config{
+		New:     newFake(stats, 2, 3),
+		Setup:   noSetup,
+		Sampler: Grid(10),
+	})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	// The 100 grid points map onto only 2 x 3 distinct parameters.
+	if len(results) != 6 {
+		t.Errorf("Run() returned %d results, want 6", len(results))
+	}
+	for key, runs := range stats.runs {
+		if runs != 1 {
+			t.Errorf("parameters %s were run %d times, want once", key, runs)
+		}
+	}
+
+	result, ok := results[Key([]float64{1, 2})]
+	if !ok {
+		t.Fatalf("Run() returned no result for 1,2")
+	}
+	want := Result{Params: []float64{1, 2}, Outputs: [][]float64{{3}, {2}}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("result for 1,2 = %+v, want %+v", result, want)
+	}
+}
+
+func TestRunBoundsWorkers(t *testing.T) {
+	stats := &fakeStats{runs: map[string]int{}}
+	results, err := Run(Config{
+		New:     newFake(stats, 50),
+		Setup:   noSetup,
+		Sampler: Grid(50),
+		Workers: 3,
+	})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if len(results) != 50 {
+		t.Errorf("Run() returned %d results, want 50", len(results))
+	}
+	if stats.maxActive > 3 {
+		t.Errorf("%d evaluations ran at once, want at most 3", stats.maxActive)
+	}
+}
+
+func TestRunSetupError(t *testing.T) {
+	setupErr := errors.New("no input data")
+	_, err := Run(Config{
+		New:     newFake(&fakeStats{runs: map[string]int{}}, 5),
+		Setup:   func(Function) error { return setupErr },
+		Sampler: Grid(5),
+		Workers: 2,
+	})
+	if !errors.Is(err, setupErr) {
+		t.Errorf("Run() error = %v, want it to wrap %v", err, setupErr)
+	}
+}
+
+func TestEvaluateError(t *testing.T) {
+	f := &fakeFunction{buckets: []int{1}, stats: &fakeStats{runs: map[string]int{}}}
+	result := evaluate(f, []float64{-1})
+	if result.Err == nil || result.Outputs != nil {
+		t.Errorf("evaluate() = %+v, want an error and no outputs", result)
+	}
+}
@@ -0,0 +1,235 @@
+//go:build !notalib
+
+package main
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+/*
+#cgo LDFLAGS: -lta_lib -lm
+#include <stdlib.h>
+#include "ta_abstract.h"
+char* getListAt(char **list, unsigned int idx)
+{
+	return list[idx];
+}
+TA_IntegerDataPair getIntegerDataPairAt( TA_IntegerDataPair* data, unsigned int index )
+{
+	return data[index];
+}
+TA_RealDataPair getRealDataPairAt( TA_RealDataPair* data, unsigned int index )
+{
+	return data[index];
+}
+*/
+import "C"
+
+// retCodeError describes a failed TA-Lib call, or returns nil if retCode is
+// TA_SUCCESS.
+func retCodeError(call string, retCode C.TA_RetCode) error {
+	if retCode == C.TA_SUCCESS {
+		return nil
+	}
+
+	var info C.TA_RetCodeInfo
+	C.TA_SetRetCodeInfo(retCode, &info)
+	return fmt.Errorf("%s: %s (%d): %s", call, C.GoString(info.enumStr), int(retCode), C.GoString(info.infoStr))
+}
+
+func getGroups() ([]string, error) {
+	var table *C.TA_StringTable
+	if err := retCodeError("TA_GroupTableAlloc", C.TA_GroupTableAlloc(&table)); err != nil {
+		return nil, err
+	}
+	defer C.TA_GroupTableFree(table)
+
+	ret := make([]string, table.size)
+	for i := C.uint(0); i < table.size; i++ {
+		ret[i] = C.GoString(C.getListAt(table.string, i))
+	}
+	return ret, nil
+}
+
+func getFunctions(group string) ([]string, error) {
+	cGroup := C.CString(group)
+	defer C.free(unsafe.Pointer(cGroup))
+
+	var table *C.TA_StringTable
+	if err := retCodeError("TA_FuncTableAlloc "+group, C.TA_FuncTableAlloc(cGroup, &table)); err != nil {
+		return nil, err
+	}
+	defer C.TA_FuncTableFree(table)
+
+	ret := make([]string, table.size)
+	for i := C.uint(0); i < table.size; i++ {
+		ret[i] = C.GoString(C.getListAt(table.string, i))
+	}
+	return ret, nil
+}
+
+func getFunctionHandle(functionName string) (*C.TA_FuncHandle, error) {
+	cName := C.CString(functionName)
+	defer C.free(unsafe.Pointer(cName))
+
+	var handle *C.TA_FuncHandle
+	if err := retCodeError("TA_GetFuncHandle "+functionName, C.TA_GetFuncHandle(cName, &handle)); err != nil {
+		return nil, err
+	}
+	return handle, nil
+}
+
+func getFunctionInfo(functionName string, handle *C.TA_FuncHandle) (C.TA_FuncInfo, error) {
+	var info *C.TA_FuncInfo
+	if err := retCodeError("TA_GetFuncInfo "+functionName, C.TA_GetFuncInfo(handle, &info)); err != nil {
+		return C.TA_FuncInfo{}, err
+	}
+	return *info, nil
+}
+
+func newOptInputSpec(index int, paramInfo *C.TA_OptInputParameterInfo) OptInputSpec {
+	spec := OptInputSpec{
+		Index:        index,
+		Type:         OptInputType(paramInfo._type),
+		Name:         C.GoString(paramInfo.paramName),
+		DisplayName:  C.GoString(paramInfo.displayName),
+		Hint:         C.GoString(paramInfo.hint),
+		Flags:        uint32(paramInfo.flags),
+		DefaultValue: float64(paramInfo.defaultValue),
+	}
+
+	switch paramInfo._type {
+	case C.TA_OptInput_IntegerRange:
+		integerRange := (*C.TA_IntegerRange)(unsafe.Pointer(paramInfo.dataSet))
+		spec.Range = &RangeSpec{
+			Min:                float64(integerRange.min),
+			Max:                float64(integerRange.max),
+			SuggestedStart:     float64(integerRange.suggested_start),
+			SuggestedEnd:       float64(integerRange.suggested_end),
+			SuggestedIncrement: float64(integerRange.suggested_increment),
+		}
+	case C.TA_OptInput_RealRange:
+		realRange := (*C.TA_RealRange)(unsafe.Pointer(paramInfo.dataSet))
+		spec.Range = &RangeSpec{
+			Min:                float64(realRange.min),
+			Max:                float64(realRange.max),
+			Precision:          int(realRange.precision),
+			SuggestedStart:     float64(realRange.suggested_start),
+			SuggestedEnd:       float64(realRange.suggested_end),
+			SuggestedIncrement: float64(realRange.suggested_increment),
+		}
+	case C.TA_OptInput_IntegerList:
+		integerList := (*C.TA_IntegerList)(unsafe.Pointer(paramInfo.dataSet))
+		for i := 0; i < int(integerList.nbElement); i++ {
+			pair := C.getIntegerDataPairAt(integerList.data, C.uint(i))
+			spec.List = append(spec.List, ListItemSpec{
+				Value: float64(pair.value),
+				Label: C.GoString(pair.string),
+			})
+		}
+	case C.TA_OptInput_RealList:
+		realList := (*C.TA_RealList)(unsafe.Pointer(paramInfo.dataSet))
+		for i := 0; i < int(realList.nbElement); i++ {
+			pair := C.getRealDataPairAt(realList.data, C.uint(i))
+			spec.List = append(spec.List, ListItemSpec{
+				Value: float64(pair.value),
+				Label: C.GoString(pair.string),
+			})
+		}
+	}
+
+	return spec
+}
+
+func newFunctionSpec(info C.TA_FuncInfo) (FunctionSpec, error) {
+	spec := FunctionSpec{
+		Name:          C.GoString(info.name),
+		Group:         C.GoString(info.group),
+		Hint:          C.GoString(info.hint),
+		CamelCaseName: C.GoString(info.camelCaseName),
+		Flags:         uint32(info.flags),
+	}
+
+	for i := 0; i < int(info.nbInput); i++ {
+		var paramInfo *C.TA_InputParameterInfo
+		retCode := C.TA_GetInputParameterInfo(info.handle, C.uint(i), &paramInfo)
+		if err := retCodeError(fmt.Sprintf("TA_GetInputParameterInfo %s %d", spec.Name, i), retCode); err != nil {
+			return spec, err
+		}
+
+		spec.Inputs = append(spec.Inputs, InputSpec{
+			Index: i,
+			Type:  InputType(paramInfo._type),
+			Name:  C.GoString(paramInfo.paramName),
+			Flags: uint32(paramInfo.flags),
+		})
+	}
+
+	for i := 0; i < int(info.nbOptInput); i++ {
+		var paramInfo *C.TA_OptInputParameterInfo
+		retCode := C.TA_GetOptInputParameterInfo(info.handle, C.uint(i), &paramInfo)
+		if err := retCodeError(fmt.Sprintf("TA_GetOptInputParameterInfo %s %d", spec.Name, i), retCode); err != nil {
+			return spec, err
+		}
+
+		spec.OptInputs = append(spec.OptInputs, newOptInputSpec(i, paramInfo))
+	}
+
+	for i := 0; i < int(info.nbOutput); i++ {
+		var paramInfo *C.TA_OutputParameterInfo
+		retCode := C.TA_GetOutputParameterInfo(info.handle, C.uint(i), &paramInfo)
+		if err := retCodeError(fmt.Sprintf("TA_GetOutputParameterInfo %s %d", spec.Name, i), retCode); err != nil {
+			return spec, err
+		}
+
+		spec.Outputs = append(spec.Outputs, OutputSpec{
+			Index: i,
+			Type:  OutputType(paramInfo._type),
+			Name:  C.GoString(paramInfo.paramName),
+			Flags: uint32(paramInfo.flags),
+		})
+	}
+
+	return spec, nil
+}
+
+// loadTALibSnapshot reads every group and function from the TA-Lib abstract
+// API, in group order.
+func loadTALibSnapshot() (Snapshot, error) {
+	snapshot := Snapshot{
+		Version:      snapshotVersion,
+		TALibVersion: C.GoString(C.TA_GetVersionString()),
+	}
+
+	groups, err := getGroups()
+	if err != nil {
+		return snapshot, err
+	}
+	for _, group := range groups {
+		functions, err := getFunctions(group)
+		if err != nil {
+			return snapshot, err
+		}
+		snapshot.Groups = append(snapshot.Groups, GroupSpec{Name: group, Functions: functions})
+
+		for _, function := range functions {
+			handle, err := getFunctionHandle(function)
+			if err != nil {
+				return snapshot, err
+			}
+			info, err := getFunctionInfo(function, handle)
+			if err != nil {
+				return snapshot, err
+			}
+
+			spec, err := newFunctionSpec(info)
+			if err != nil {
+				return snapshot, err
+			}
+			snapshot.Functions = append(snapshot.Functions, spec)
+		}
+	}
+
+	return snapshot, nil
+}
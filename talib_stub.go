@@ -0,0 +1,9 @@
+//go:build notalib
+
+package main
+
+import "errors"
+
+func loadTALibSnapshot() (Snapshot, error) {
+	return Snapshot{}, errors.New("built with the notalib tag, TA-Lib metadata is only available from a snapshot (-from)")
+}
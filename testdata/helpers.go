@@ -0,0 +1,71 @@
+package gotalib
+
+// Stand-ins for the helpers gotalib writes by hand, so that the generated code
+// can be type-checked without it.
+
+/*
+#include <stdlib.h>
+#include "ta_abstract.h"
+*/
+import "C"
+
+import "unsafe"
+
+func helper_getFunctionHandle(name string, handle **C.TA_FuncHandle) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	C.TA_GetFuncHandle(cName, handle)
+}
+
+func helper_paramHolderAlloc(handle *C.TA_FuncHandle, params **C.TA_ParamHolder) {
+	C.TA_ParamHolderAlloc(handle, params)
+}
+
+func helper_convertGoFloat64ArrayToTaRealArray(in []float64, out *[]C.TA_Real) {
+	*out = make([]C.TA_Real, len(in))
+	for i, v := range in {
+		(*out)[i] = C.TA_Real(v)
+	}
+}
+
+func helper_convertTaRealArrayToGoFloat64Array(in []C.TA_Real, out *[]float64) {
+	*out = make([]float64, len(in))
+	for i, v := range in {
+		(*out)[i] = float64(v)
+	}
+}
+
+func helper_convertTaIntegerArrayToGoFloat64Array(in []C.TA_Integer, out *[]float64) {
+	*out = make([]float64, len(in))
+	for i, v := range in {
+		(*out)[i] = float64(v)
+	}
+}
+
+func helper_setInputDataReal(params *C.TA_ParamHolder, index int, data *C.TA_Real) {
+	C.TA_SetInputParamRealPtr(params, C.uint(index), data)
+}
+
+func helper_setInputDataPrice(params *C.TA_ParamHolder, index int, open, high, low, close, volume, openInterest *C.TA_Real) {
+	C.TA_SetInputParamPricePtr(params, C.uint(index), open, high, low, close, volume, openInterest)
+}
+
+func helper_setOptInputDataInteger(params *C.TA_ParamHolder, index int, value int) {
+	C.TA_SetOptInputParamInteger(params, C.uint(index), C.TA_Integer(value))
+}
+
+func helper_setOptInputDataReal(params *C.TA_ParamHolder, index int, value float64) {
+	C.TA_SetOptInputParamReal(params, C.uint(index), C.TA_Real(value))
+}
+
+func helper_setOutputParamRealPtr(params *C.TA_ParamHolder, index int, out *C.TA_Real) {
+	C.TA_SetOutputParamRealPtr(params, C.uint(index), out)
+}
+
+func helper_setOutputParamIntegerPtr(params *C.TA_ParamHolder, index int, out *C.TA_Integer) {
+	C.TA_SetOutputParamIntegerPtr(params, C.uint(index), out)
+}
+
+func helper_callFunction(params *C.TA_ParamHolder, start, end int, outBegIdx, outNbElement *C.TA_Integer) {
+	C.TA_CallFunc(params, C.TA_Integer(start), C.TA_Integer(end), outBegIdx, outNbElement)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// priceComponents are the price input components in TA_SetInputParamPricePtr
+// order, with the TA_IN_PRICE_* flag for each.
+var priceComponents = []struct {
+	name string
+	flag uint32
+}{
+	{"open", 0x01},
+	{"high", 0x02},
+	{"low", 0x04},
+	{"close", 0x08},
+	{"volume", 0x10},
+	{"openInterest", 0x20},
+}
+
+// wrapperReservedNames are the identifiers the typed wrappers use themselves.
+var wrapperReservedNames = []string{"C", "err", "handle", "params", "begIdx", "outBegIdx", "numElements", "len", "make", "nil"}
+
+// wrapperInputArray is a Go parameter and the local variable holding its
+// TA_Real copy.
+type wrapperInputArray struct {
+	Name     string
+	Variable string
+}
+
+type wrapperInput struct {
+	Index int
+	Type  InputType
+	// Arrays holds the single array of a real input, and one entry per price
+	// component of a price input, with an empty name for the components the
+	// function does not read.
+	Arrays []wrapperInputArray
+}
+
+type wrapperParam struct {
+	Index int
+	Name  string
+	Type  string
+}
+
+type wrapperOutput struct {
+	Index int
+	Name  string
+	Type  OutputType
+}
+
+// wrapperData describes the strongly typed function generated for a TA-Lib
+// function, with Go parameter names derived from the TA-Lib ones.
+type wrapperData struct {
+	FunctionSpec
+
+	// GoName is the name of the Go function, which is TA-Lib's name unless
+	// another identifier of the package already has it.
+	GoName string
+
+	Inputs    []wrapperInput
+	OptInputs []wrapperParam
+	Outputs   []wrapperOutput
+
+	// LengthName is the parameter whose length is the number of bars.
+	LengthName string
+	InputNames []string
+
+	Params  string
+	Results string
+}
+
+// joinParams joins parameters into a Go parameter list, sharing the type
+// between neighbours of the same type as in (fastPeriod, slowPeriod int).
+func joinParams(params []wrapperParam) string {
+	parts := []string{}
+	for i, param := range params {
+		if i+1 < len(params) && params[i+1].Type == param.Type {
+			parts = append(parts, param.Name)
+		} else {
+			parts = append(parts, param.Name+" "+param.Type)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// goParamName turns a TA-Lib parameter name such as optInMAType into a Go
+// parameter name such as maType.
+func goParamName(paramName string, prefix string) string {
+	name := strings.TrimPrefix(paramName, prefix)
+	if name == "" {
+		name = paramName
+	}
+
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	// Keep the capital that starts the next word, so MACDSignal becomes
+	// macdSignal rather than macdsignal.
+	if upper > 1 && upper < len(runes) && unicode.IsLower(runes[upper]) {
+		upper--
+	}
+	if upper == 0 {
+		upper = 1
+	}
+
+	return strings.ToLower(string(runes[:upper])) + string(runes[upper:])
+}
+
+type nameSet map[string]bool
+
+// claim returns name if it is free, otherwise the fallback, and reserves it.
+func (s nameSet) claim(name, fallback string) string {
+	if s[name] || token.IsKeyword(name) {
+		name = fallback
+	}
+	for i := 1; s[name] || token.IsKeyword(name); i++ {
+		name = fallback + strconv.Itoa(i)
+	}
+	s[name] = true
+	return name
+}
+
+// packageNames returns the package level identifiers declared in sources.
+func packageNames(sources []output) (nameSet, error) {
+	names := nameSet{}
+	fset := token.NewFileSet()
+	for _, source := range sources {
+		file, err := parser.ParseFile(fset, source.filename, source.source, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					names[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						names[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							names[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// newWrapperData describes the typed function for spec, naming it so that it
+// does not clash with packageNames, to which its name is added.
+func newWrapperData(spec FunctionSpec, packageNames nameSet) wrapperData {
+	data := wrapperData{
+		FunctionSpec: spec,
+		// T3's constructor, for one, is also called T3.
+		GoName: packageNames.claim(spec.Name, spec.Name+"Func"),
+	}
+
+	names := nameSet{}
+	for _, name := range wrapperReservedNames {
+		names[name] = true
+	}
+
+	numPriceInputs := 0
+	for _, input := range spec.Inputs {
+		if input.Type == InputPrice {
+			numPriceInputs++
+		}
+	}
+
+	for _, input := range spec.Inputs {
+		wrapperInput := wrapperInput{Index: input.Index, Type: input.Type}
+
+		if input.Type == InputPrice {
+			for _, component := range priceComponents {
+				if input.Flags&component.flag == 0 {
+					wrapperInput.Arrays = append(wrapperInput.Arrays, wrapperInputArray{})
+					continue
+				}
+
+				name := component.name
+				if numPriceInputs > 1 {
+					name += strconv.Itoa(input.Index)
+				}
+				name = names.claim(name, name+"Input")
+				wrapperInput.Arrays = append(wrapperInput.Arrays, wrapperInputArray{
+					Name:     name,
+					Variable: "in" + strconv.Itoa(input.Index) + strings.ToUpper(component.name[:1]) + component.name[1:],
+				})
+				data.InputNames = append(data.InputNames, name)
+			}
+		} else {
+			name := names.claim(goParamName(input.Name, "in"), goParamName(input.Name, ""))
+			wrapperInput.Arrays = append(wrapperInput.Arrays, wrapperInputArray{
+				Name:     name,
+				Variable: "in" + strconv.Itoa(input.Index),
+			})
+			data.InputNames = append(data.InputNames, name)
+		}
+
+		data.Inputs = append(data.Inputs, wrapperInput)
+	}
+
+	if len(data.InputNames) > 0 {
+		data.LengthName = data.InputNames[0]
+	}
+
+	for _, optInput := range spec.OptInputs {
+		var paramType string
+		switch optInput.Type {
+		case OptInputRealRange, OptInputRealList:
+			paramType = "float64"
+		case OptInputIntegerRange, OptInputIntegerList:
+			paramType = "int"
+			if optInput.IsMAType() {
+				paramType = "MAType"
+			}
+		default:
+			// Optional inputs of unknown types are left at their default.
+			continue
+		}
+
+		data.OptInputs = append(data.OptInputs, wrapperParam{
+			Index: optInput.Index,
+			Name:  names.claim(goParamName(optInput.Name, "optIn"), goParamName(optInput.Name, "")),
+			Type:  paramType,
+		})
+	}
+
+	for _, output := range spec.Outputs {
+		data.Outputs = append(data.Outputs, wrapperOutput{
+			Index: output.Index,
+			Name:  names.claim(goParamName(output.Name, "out"), goParamName(output.Name, "")),
+			Type:  output.Type,
+		})
+	}
+
+	params := []wrapperParam{}
+	for _, name := range data.InputNames {
+		params = append(params, wrapperParam{Name: name, Type: "[]float64"})
+	}
+	data.Params = joinParams(append(params, data.OptInputs...))
+
+	results := []wrapperParam{}
+	for _, output := range data.Outputs {
+		resultType := "[]float64"
+		if output.Type == OutputInteger {
+			resultType = "[]int"
+		}
+		results = append(results, wrapperParam{Name: output.Name, Type: resultType})
+	}
+	results = append(results, wrapperParam{Name: "begIdx", Type: "int"})
+	data.Results = joinParams(append(results, wrapperParam{Name: "err", Type: "error"}))
+
+	return data
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoParamName(t *testing.T) {
+	tests := []struct {
+		paramName string
+		prefix    string
+		want      string
+	}{
+		{"optInTimePeriod", "optIn", "timePeriod"},
+		{"optInMAType", "optIn", "maType"},
+		{"outMACDSignal", "out", "macdSignal"},
+		{"outMACD", "out", "macd"},
+		{"inReal", "in", "real"},
+		{"inReal0", "in", "real0"},
+		{"optInVFactor", "optIn", "vFactor"},
+		{"outInteger", "", "outInteger"},
+		{"in", "in", "in"},
+	}
+
+	for _, test := range tests {
+		if got := goParamName(test.paramName, test.prefix); got != test.want {
+			t.Errorf("goParamName(%q, %q) = %q, want %q", test.paramName, test.prefix, got, test.want)
+		}
+	}
+}
+
+func TestNameSetClaim(t *testing.T) {
+	names := nameSet{"taken": true}
+
+	claims := []struct {
+		name, fallback string
+		want           string
+	}{
+		{"free", "freeInput", "free"},
+		{"taken", "takenInput", "takenInput"},
+		{"taken", "takenInput", "takenInput1"},
+		{"taken", "takenInput", "takenInput2"},
+		{"type", "typeInput", "typeInput"},
+		{"free", "free", "free1"},
+	}
+
+	for _, claim := range claims {
+		if got := names.claim(claim.name, claim.fallback); got != claim.want {
+			t.Errorf("claim(%q, %q) = %q, want %q", claim.name, claim.fallback, got, claim.want)
+		}
+		if !names[claim.want] {
+			t.Errorf("claim(%q, %q) did not reserve %q", claim.name, claim.fallback, claim.want)
+		}
+	}
+}
+
+func TestPackageNames(t *testing.T) {
+	source := `package gotalib
+
+import "fmt"
+
+type MAType int
+
+const (
+	MATypeSMA MAType = 0
+	MATypeEMA MAType = 1
+)
+
+var ErrEmptyInput, ErrBadInputIndex = fmt.Errorf("x"), fmt.Errorf("y")
+
+func Sma() TA_Function { return nil }
+
+func (t MAType) String() string { return "" }
+`
+
+	got, err := packageNames([]output{{"a.go", []byte(source)}})
+	if err != nil {
+		t.Fatalf("packageNames() failed: %v", err)
+	}
+
+	// Methods and imports are not package level identifiers.
+	want := nameSet{"MAType": true, "MATypeSMA": true, "MATypeEMA": true, "ErrEmptyInput": true, "ErrBadInputIndex": true, "Sma": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("packageNames() = %v, want %v", got, want)
+	}
+}
+
+func TestNewWrapperData(t *testing.T) {
+	timePeriod := OptInputSpec{Index: 0, Type: OptInputIntegerRange, Name: "optInTimePeriod"}
+
+	tests := []struct {
+		name         string
+		spec         FunctionSpec
+		packageNames nameSet
+		wantGoName   string
+		wantInputs   []string
+		wantParams   string
+		wantResults  string
+	}{
+		{
+			name: "price input",
+			spec: FunctionSpec{
+				Name:      "ATR",
+				Inputs:    []InputSpec{{Index: 0, Type: InputPrice, Name: "inPriceHLC", Flags: 0x02 | 0x04 | 0x08}},
+				OptInputs: []OptInputSpec{timePeriod},
+				Outputs:   []OutputSpec{{Index: 0, Type: OutputReal, Name: "outReal"}},
+			},
+			wantGoName:  "ATR",
+			wantInputs:  []string{"high", "low", "close"},
+			wantParams:  "high, low, close []float64, timePeriod int",
+			wantResults: "real []float64, begIdx int, err error",
+		},
+		{
+			name: "two price inputs",
+			spec: FunctionSpec{
+				Name: "TWO",
+				Inputs: []InputSpec{
+					{Index: 0, Type: InputPrice, Name: "inPrice0", Flags: 0x08},
+					{Index: 1, Type: InputPrice, Name: "inPrice1", Flags: 0x08},
+				},
+				Outputs: []OutputSpec{{Index: 0, Type: OutputReal, Name: "outReal"}},
+			},
+			wantGoName:  "TWO",
+			wantInputs:  []string{"close0", "close1"},
+			wantParams:  "close0, close1 []float64",
+			wantResults: "real []float64, begIdx int, err error",
+		},
+		{
+			name: "moving average type, real and unknown optional inputs",
+			spec: FunctionSpec{
+				Name:   "BBANDS",
+				Inputs: []InputSpec{{Index: 0, Type: InputReal, Name: "inReal"}},
+				OptInputs: []OptInputSpec{
+					timePeriod,
+					{Index: 1, Type: OptInputRealRange, Name: "optInNbDevUp"},
+					{Index: 2, Type: OptInputType(9), Name: "optInFuture"},
+					{Index: 3, Type: OptInputIntegerList, Name: "optInMAType"},
+				},
+				Outputs: []OutputSpec{
+					{Index: 0, Type: OutputReal, Name: "outRealUpperBand"},
+					{Index: 1, Type: OutputInteger, Name: "outInteger"},
+				},
+			},
+			wantGoName:  "BBANDS",
+			wantInputs:  []string{"real"},
+			wantParams:  "real []float64, timePeriod int, nbDevUp float64, maType MAType",
+			wantResults: "realUpperBand []float64, integer []int, begIdx int, err error",
+		},
+		{
+			name: "names taken by the wrapper itself",
+			spec: FunctionSpec{
+				Name:      "CLASH",
+				Inputs:    []InputSpec{{Index: 0, Type: InputReal, Name: "inParams"}},
+				OptInputs: []OptInputSpec{{Index: 0, Type: OptInputIntegerRange, Name: "optInParams"}},
+				Outputs:   []OutputSpec{{Index: 0, Type: OutputReal, Name: "outType"}},
+			},
+			wantGoName:  "CLASH",
+			wantInputs:  []string{"inParams"},
+			wantParams:  "inParams []float64, optInParams int",
+			wantResults: "outType []float64, begIdx int, err error",
+		},
+		{
+			name: "function name taken by the package",
+			spec: FunctionSpec{
+				Name:          "T3",
+				CamelCaseName: "T3",
+				Inputs:        []InputSpec{{Index: 0, Type: InputReal, Name: "inReal"}},
+				Outputs:       []OutputSpec{{Index: 0, Type: OutputReal, Name: "outReal"}},
+			},
+			packageNames: nameSet{"T3": true},
+			wantGoName:   "T3Func",
+			wantInputs:   []string{"real"},
+			wantParams:   "real []float64",
+			wantResults:  "outReal []float64, begIdx int, err error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			packageNames := nameSet{}
+			for name := range test.packageNames {
+				packageNames[name] = true
+			}
+
+			data := newWrapperData(test.spec, packageNames)
+			if data.GoName != test.wantGoName {
+				t.Errorf("GoName = %q, want %q", data.GoName, test.wantGoName)
+			}
+			if !packageNames[data.GoName] {
+				t.Errorf("%s was not added to the package names", data.GoName)
+			}
+			if !reflect.DeepEqual(data.InputNames, test.wantInputs) {
+				t.Errorf("InputNames = %q, want %q", data.InputNames, test.wantInputs)
+			}
+			if data.LengthName != test.wantInputs[0] {
+				t.Errorf("LengthName = %q, want %q", data.LengthName, test.wantInputs[0])
+			}
+			if data.Params != test.wantParams {
+				t.Errorf("Params = %q, want %q", data.Params, test.wantParams)
+			}
+			if data.Results != test.wantResults {
+				t.Errorf("Results = %q, want %q", data.Results, test.wantResults)
+			}
+		})
+	}
+}